@@ -0,0 +1,110 @@
+package iter2
+
+import (
+	"context"
+	"iter"
+)
+
+// FromChan returns an iterator over the values received from ch, until ch is closed.
+func FromChan[T any](ch <-chan T) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// FromChanContext is like [FromChan], but also stops when ctx is done,
+// in which case ctx.Err() is yielded with the zero value of T as the final element.
+func FromChanContext[T any](ctx context.Context, ch <-chan T) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok || !yield(v, nil) {
+					return
+				}
+			case <-ctx.Done():
+				var zero T
+				yield(zero, ctx.Err())
+				return
+			}
+		}
+	}
+}
+
+// FromChan2 returns an iterator over the key-value pairs received from ch, until ch is closed.
+func FromChan2[K, V any](ch <-chan struct {
+	K K
+	V V
+}) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for pair := range ch {
+			if !yield(pair.K, pair.V) {
+				return
+			}
+		}
+	}
+}
+
+// ToChan returns a channel with the buffer size that receives the values of seq.
+// Seq is iterated in a new goroutine, which stops when seq ends or ctx is done,
+// and then closes the channel.
+func ToChan[T any](ctx context.Context, seq iter.Seq[T], buffer int) <-chan T {
+	ch := make(chan T, buffer)
+	go func() {
+		defer close(ch)
+		for v := range seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// ToChan2 is like [ToChan], but receives the key-value pairs of seq2.
+// An iter.Seq2[T, error] can be sent this way with its errors.
+func ToChan2[K, V any](ctx context.Context, seq2 iter.Seq2[K, V], buffer int) <-chan struct {
+	K K
+	V V
+} {
+	ch := make(chan struct {
+		K K
+		V V
+	}, buffer)
+	go func() {
+		defer close(ch)
+		for k, v := range seq2 {
+			select {
+			case ch <- struct {
+				K K
+				V V
+			}{k, v}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
+
+// Drain receives and discards the values from ch until ch is closed or ctx is done.
+// Drain returns ctx.Err() if ctx is done before ch is closed, or nil otherwise.
+// Drain is useful to wait for a producer to finish without leaking it.
+func Drain[T any](ctx context.Context, ch <-chan T) error {
+	for {
+		select {
+		case _, ok := <-ch:
+			if !ok {
+				return nil
+			}
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
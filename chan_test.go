@@ -0,0 +1,107 @@
+package iter2
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestFromChan(t *testing.T) {
+	ch := make(chan int, 3)
+	ch <- 1
+	ch <- 2
+	ch <- 3
+	close(ch)
+	if s := slices.Collect(FromChan(ch)); !slices.Equal(s, []int{1, 2, 3}) {
+		t.Fatal(s)
+	}
+}
+
+func TestFromChanContext(t *testing.T) {
+	ch := make(chan int, 2)
+	ch <- 1
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var s []int
+	var err error
+	for v, e := range FromChanContext(ctx, ch) {
+		if e != nil {
+			err = e
+			break
+		}
+		s = append(s, v)
+		cancel()
+	}
+	if !slices.Equal(s, []int{1}) {
+		t.Fatal(s)
+	}
+	if err != context.Canceled {
+		t.Fatal(err)
+	}
+
+	ch <- 2
+	close(ch)
+	if m := maps.Collect(FromChanContext(context.Background(), ch)); !maps.Equal(m, map[int]error{2: nil}) {
+		t.Fatal(m)
+	}
+}
+
+func TestFromChan2(t *testing.T) {
+	ch := make(chan struct {
+		K int
+		V string
+	}, 2)
+	ch <- struct {
+		K int
+		V string
+	}{1, "one"}
+	ch <- struct {
+		K int
+		V string
+	}{2, "two"}
+	close(ch)
+	if m := maps.Collect(FromChan2(ch)); !maps.Equal(m, map[int]string{1: "one", 2: "two"}) {
+		t.Fatal(m)
+	}
+}
+
+func TestToChan(t *testing.T) {
+	ch := ToChan(context.Background(), slices.Values([]int{1, 2, 3}), 1)
+	if s := slices.Collect(FromChan(ch)); !slices.Equal(s, []int{1, 2, 3}) {
+		t.Fatal(s)
+	}
+
+	// cancel
+	stopped := make(chan struct{})
+	ctx, cancel := context.WithCancel(context.Background())
+	ch = ToChan(ctx, func(yield func(int) bool) {
+		defer close(stopped)
+		for i := 0; yield(i); i++ {
+		}
+	}, 0)
+	if v := <-ch; v != 0 {
+		t.Fatal(v)
+	}
+	cancel()
+	<-stopped
+	if err := Drain(context.Background(), ch); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestToChan2(t *testing.T) {
+	ch := ToChan2(context.Background(), slices.All([]string{"a", "b"}), 0)
+	if m := maps.Collect(FromChan2(ch)); !maps.Equal(m, map[int]string{0: "a", 1: "b"}) {
+		t.Fatal(m)
+	}
+}
+
+func TestDrain(t *testing.T) {
+	ch := make(chan int)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := Drain(ctx, ch); err != context.Canceled {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,220 @@
+package iter2
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DiffKind is the kind of a difference between two file trees.
+type DiffKind int
+
+const (
+	Added       DiffKind = iota + 1 // The entry is only in tree B.
+	Removed                         // The entry is only in tree A.
+	Modified                        // The file is in both trees, but with different contents.
+	TypeChanged                     // The entry is in both trees, but with different types, such as a file and a directory.
+)
+
+func (k DiffKind) String() string {
+	switch k {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Modified:
+		return "Modified"
+	case TypeChanged:
+		return "TypeChanged"
+	}
+	return "DiffKind(" + strconv.Itoa(int(k)) + ")"
+}
+
+// TreeDiff is a difference between two file trees.
+type TreeDiff struct {
+	Kind DiffKind
+	// RelPath is the slash-separated path of the entry relative to the roots.
+	RelPath string
+	// A is the entry in tree A, nil if Kind is Added.
+	A *DirEntry
+	// B is the entry in tree B, nil if Kind is Removed.
+	B *DirEntry
+}
+
+// DiffOptions controls how [DiffTreesWith] compares the trees.
+type DiffOptions struct {
+	// If CompareContent is true, two regular files of the same size are Modified if their
+	// SHA-256 hashes differ. Otherwise, two files are Modified if their sizes or modification times differ.
+	CompareContent bool
+}
+
+// DiffTrees returns an iterator over the differences between two file trees.
+// Files are Modified if their sizes or modification times differ. See [DiffTreesWith].
+func DiffTrees(fsA fs.FS, rootA string, fsB fs.FS, rootB string) iter.Seq2[*TreeDiff, error] {
+	return DiffTreesWith(fsA, rootA, fsB, rootB, nil)
+}
+
+// compareRelPath compares two relative paths yielded by [WalkDir] in the order they are walked.
+func compareRelPath(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == ".": // root first
+		return -1
+	case b == ".":
+		return 1
+	}
+	return slices.Compare(strings.Split(a, "/"), strings.Split(b, "/"))
+}
+
+// inDir reports whether the relative path rel is in the directory dir. Empty dir contains nothing.
+func inDir(rel, dir string) bool {
+	switch dir {
+	case "":
+		return false
+	case ".":
+		return rel != "."
+	}
+	return strings.HasPrefix(rel, dir+"/")
+}
+
+// DiffTreesWith returns an iterator over the differences between the file tree rooted at rootA in fsA
+// and the one rooted at rootB in fsB, by walking both trees in lockstep with [WalkDir].
+// The differences are yielded in the order of WalkDir. Every entry in a directory only in one tree is
+// yielded too. Directories in both trees are never Modified.
+//
+// The errors walking the trees or reading the files are yielded with nil *TreeDiff, and the walk continues,
+// unless the error is of a root, in which case nothing else is yielded. If a directory can not be read in
+// either tree, only the error is yielded, and the entries in the directory are not compared in both trees.
+// Nil opts is the same as the zero value.
+func DiffTreesWith(fsA fs.FS, rootA string, fsB fs.FS, rootB string, opts *DiffOptions) iter.Seq2[*TreeDiff, error] {
+	if opts == nil {
+		opts = &DiffOptions{}
+	}
+	return func(yield func(*TreeDiff, error) bool) {
+		nextA, stopA := iter.Pull2(WalkDir(fsA, rootA))
+		defer stopA()
+		nextB, stopB := iter.Pull2(WalkDir(fsB, rootB))
+		defer stopB()
+		stopped := false
+		// RelPaths of the directories that can not be read in either tree, whose entries are skipped.
+		// Only the directories not in the others are recorded.
+		var unreadable []string
+		skipped := func(rel string) bool {
+			return slices.ContainsFunc(unreadable, func(dir string) bool { return inDir(rel, dir) })
+		}
+		// next returns the next entry of a tree, yielding the errors.
+		next := func(pull func() (*DirEntry, error, bool)) *DirEntry {
+			for !stopped {
+				d, err, ok := pull()
+				if !ok {
+					return nil
+				}
+				if err == nil {
+					return d
+				}
+				if d.Entry != nil && skipped(d.RelPath) {
+					continue // in a directory skipped
+				}
+				if walkErr, ok := err.(*WalkError); ok && walkErr.Op == "readdir" {
+					unreadable = append(unreadable, d.RelPath)
+				}
+				if !yield(nil, err) || d.Entry == nil {
+					stopped = true
+				}
+			}
+			return nil
+		}
+		a, b := next(nextA), next(nextB)
+		for !stopped && (a != nil || b != nil) {
+			// The entries in an unreadable directory can not be compared. Skip them in both trees.
+			if a != nil && skipped(a.RelPath) {
+				a = next(nextA)
+				continue
+			}
+			if b != nil && skipped(b.RelPath) {
+				b = next(nextB)
+				continue
+			}
+			var diff *TreeDiff
+			var err error
+			var c int
+			switch {
+			case a == nil:
+				c = 1
+			case b == nil:
+				c = -1
+			default:
+				c = compareRelPath(a.RelPath, b.RelPath)
+			}
+			switch {
+			case c < 0:
+				diff = &TreeDiff{Kind: Removed, RelPath: a.RelPath, A: a}
+			case c > 0:
+				diff = &TreeDiff{Kind: Added, RelPath: b.RelPath, B: b}
+			case a.Entry.Type() != b.Entry.Type():
+				diff = &TreeDiff{Kind: TypeChanged, RelPath: a.RelPath, A: a, B: b}
+			case !a.Entry.IsDir():
+				var modified bool
+				if modified, err = fileModified(fsA, a, fsB, b, opts.CompareContent); modified {
+					diff = &TreeDiff{Kind: Modified, RelPath: a.RelPath, A: a, B: b}
+				}
+			}
+			if (diff != nil || err != nil) && !yield(diff, err) {
+				return
+			}
+			if c <= 0 {
+				a = next(nextA)
+			}
+			if c >= 0 {
+				b = next(nextB)
+			}
+		}
+	}
+}
+
+// fileModified reports whether the file a in fsA and the file b in fsB of the same type are different.
+func fileModified(fsA fs.FS, a *DirEntry, fsB fs.FS, b *DirEntry, compareContent bool) (bool, error) {
+	infoA, err := a.Info()
+	if err != nil {
+		return false, err
+	}
+	infoB, err := b.Info()
+	if err != nil {
+		return false, err
+	}
+	if infoA.Size() != infoB.Size() {
+		return true, nil
+	}
+	if !compareContent || !infoA.Mode().IsRegular() {
+		return !infoA.ModTime().Equal(infoB.ModTime()), nil
+	}
+	hashA, err := hashFile(fsA, a.Path)
+	if err != nil {
+		return false, err
+	}
+	hashB, err := hashFile(fsB, b.Path)
+	if err != nil {
+		return false, err
+	}
+	return !bytes.Equal(hashA, hashB), nil
+}
+
+// hashFile returns the SHA-256 hash of the named file in fsys.
+func hashFile(fsys fs.FS, name string) ([]byte, error) {
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, walkError("read", name, err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return nil, walkError("read", name, err)
+	}
+	return h.Sum(nil), nil
+}
@@ -0,0 +1,177 @@
+package iter2
+
+import (
+	"cmp"
+	"errors"
+	"io/fs"
+	"iter"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+type diffResult struct {
+	Kind DiffKind
+	Path string
+}
+
+func collectDiffs(t *testing.T, seq iter.Seq2[*TreeDiff, error]) (s []diffResult) {
+	for diff, err := range seq {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (diff.A == nil) != (diff.Kind == Added) || (diff.B == nil) != (diff.Kind == Removed) {
+			t.Fatal(diff)
+		}
+		s = append(s, diffResult{diff.Kind, diff.RelPath})
+	}
+	return
+}
+
+var diffTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var diffFSA = fstest.MapFS{
+	"a/b.txt":     {Data: []byte("b"), ModTime: diffTime},
+	"a.txt":       {Data: []byte("a"), ModTime: diffTime},
+	"same.txt":    {Data: []byte("same"), ModTime: diffTime},
+	"touched.txt": {Data: []byte("touched"), ModTime: diffTime},
+	"edited.txt":  {Data: []byte("edited"), ModTime: diffTime},
+	"grown.txt":   {Data: []byte("grown"), ModTime: diffTime},
+	"old/x":       {ModTime: diffTime},
+	"old/y/z":     {ModTime: diffTime},
+	"file2dir":    {ModTime: diffTime},
+	"root/t":      {ModTime: diffTime},
+}
+
+var diffFSB = fstest.MapFS{
+	"a/b.txt":     {Data: []byte("b"), ModTime: diffTime},
+	"a/c.txt":     {Data: []byte("c"), ModTime: diffTime},
+	"a.txt":       {Data: []byte("a"), ModTime: diffTime},
+	"same.txt":    {Data: []byte("same"), ModTime: diffTime},
+	"touched.txt": {Data: []byte("touched"), ModTime: diffTime.Add(time.Second)},
+	"edited.txt":  {Data: []byte("EDITED"), ModTime: diffTime},
+	"grown.txt":   {Data: []byte("grown!"), ModTime: diffTime},
+	"new.txt":     {ModTime: diffTime},
+	"file2dir/f":  {ModTime: diffTime},
+	"root/t":      {ModTime: diffTime},
+}
+
+func TestDiffTrees(t *testing.T) {
+	s := collectDiffs(t, DiffTrees(diffFSA, ".", diffFSB, "."))
+	if !slices.Equal(s, []diffResult{
+		{Added, "a/c.txt"},
+		{TypeChanged, "file2dir"},
+		{Added, "file2dir/f"},
+		{Modified, "grown.txt"},
+		{Added, "new.txt"},
+		{Removed, "old"},
+		{Removed, "old/x"},
+		{Removed, "old/y"},
+		{Removed, "old/y/z"},
+		{Modified, "touched.txt"},
+	}) {
+		t.Fatal(s)
+	}
+
+	s = collectDiffs(t, DiffTreesWith(diffFSA, ".", diffFSB, ".", &DiffOptions{CompareContent: true}))
+	if !slices.Equal(s, []diffResult{
+		{Added, "a/c.txt"},
+		{Modified, "edited.txt"},
+		{TypeChanged, "file2dir"},
+		{Added, "file2dir/f"},
+		{Modified, "grown.txt"},
+		{Added, "new.txt"},
+		{Removed, "old"},
+		{Removed, "old/x"},
+		{Removed, "old/y"},
+		{Removed, "old/y/z"},
+	}) {
+		t.Fatal(s)
+	}
+
+	// different roots
+	s = collectDiffs(t, DiffTrees(diffFSA, "a", diffFSB, "a"))
+	if !slices.Equal(s, []diffResult{{Added, "c.txt"}}) {
+		t.Fatal(s)
+	}
+	s = collectDiffs(t, DiffTrees(diffFSA, "root", diffFSA, "root"))
+	if len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if s := collectDiffs(t, Take2(DiffTrees(diffFSA, ".", diffFSB, "."), 2)); len(s) != 2 {
+		t.Fatal(s)
+	}
+}
+
+func TestCompareRelPath(t *testing.T) {
+	paths := []string{".", "a", "a/b", "a/b/c", "a.txt", "a0", "b"}
+	for i := range paths {
+		for j := range paths {
+			if c := compareRelPath(paths[i], paths[j]); c != cmp.Compare(i, j) {
+				t.Fatal(paths[i], paths[j], c)
+			}
+		}
+	}
+}
+
+func TestDiffTreesErr(t *testing.T) {
+	var errs []error
+	var diffs []*TreeDiff
+	for diff, err := range DiffTrees(diffFSA, ".", diffFSB, "NO THIS FILE") {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		diffs = append(diffs, diff)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrNotExist) || len(diffs) != 0 {
+		t.Fatal(errs, diffs)
+	}
+
+	// unreadable directories
+	for _, c := range []struct {
+		fsA, fsB fs.FS
+		want     []diffResult
+	}{
+		{diffFSA, unreadableFS{diffFSB, []string{"a"}}, []diffResult{{Added, "file2dir/f"}, {Removed, "old"}, {Removed, "old/x"}, {Removed, "old/y"}, {Removed, "old/y/z"}}},
+		{unreadableFS{diffFSA, []string{"a"}}, diffFSB, []diffResult{{Added, "file2dir/f"}, {Removed, "old"}, {Removed, "old/x"}, {Removed, "old/y"}, {Removed, "old/y/z"}}},
+		{unreadableFS{diffFSA, []string{"old"}}, unreadableFS{diffFSB, []string{"file2dir"}}, []diffResult{{Added, "a/c.txt"}, {Removed, "old"}}},
+	} {
+		errs = nil
+		var s []diffResult
+		for diff, err := range DiffTrees(c.fsA, ".", c.fsB, ".") {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if diff.Kind == Modified || diff.Kind == TypeChanged || diff.RelPath == "new.txt" {
+				continue
+			}
+			s = append(s, diffResult{diff.Kind, diff.RelPath})
+		}
+		if len(errs) == 0 || !errors.Is(errs[0], fs.ErrPermission) || !slices.Equal(s, c.want) {
+			t.Fatal(errs, s)
+		}
+	}
+
+	// unreadable directory in an unreadable directory
+	nestedA := unreadableFS{fstest.MapFS{"x/y": {}, "w": {}}, []string{"x"}}
+	nestedB := unreadableFS{fstest.MapFS{"x/sub/s": {}, "x/z": {}, "w": {}, "zz": {}}, []string{"x/sub"}}
+	errs = nil
+	var s []diffResult
+	for diff, err := range DiffTrees(nestedA, ".", nestedB, ".") {
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		s = append(s, diffResult{diff.Kind, diff.RelPath})
+	}
+	var walkErr *WalkError
+	if len(errs) != 1 || !errors.As(errs[0], &walkErr) || walkErr.Path != "x" ||
+		!slices.Equal(s, []diffResult{{Added, "zz"}}) {
+		t.Fatal(errs, s)
+	}
+}
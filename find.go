@@ -0,0 +1,127 @@
+package iter2
+
+import (
+	"io/fs"
+	"iter"
+	"path"
+	"slices"
+	"time"
+)
+
+// Predicate reports whether a DirEntry is selected. Predicates needing [fs.FileInfo] use the cached
+// DirEntry.Info, so it is read at most once however many predicates are combined.
+// An entry whose FileInfo can not be read satisfies none of them.
+type Predicate func(d *DirEntry) bool
+
+// Find returns an iterator over the entries in seq satisfying pred, such as the ones yielded by [WalkDir].
+// The errors in seq are always yielded.
+func Find(seq iter.Seq2[*DirEntry, error], pred Predicate) iter.Seq2[*DirEntry, error] {
+	return func(yield func(*DirEntry, error) bool) {
+		for d, err := range seq {
+			if err == nil && !pred(d) {
+				continue
+			}
+			if !yield(d, err) {
+				return
+			}
+		}
+	}
+}
+
+// Prune returns a Predicate which is the same as pred, but also skips the directories not satisfying pred
+// by calling SkipDir, so that nothing in them is walked. The directories are skipped whenever the returned
+// Predicate is called, even if the result is negated by [Not] or unused by [And] and [Or].
+func Prune(pred Predicate) Predicate {
+	return func(d *DirEntry) bool {
+		if pred(d) {
+			return true
+		}
+		if d.Entry != nil && d.Entry.IsDir() {
+			d.SkipDir()
+		}
+		return false
+	}
+}
+
+// And returns a Predicate satisfied if all of preds are satisfied.
+// The preds are called in order until one is not satisfied.
+func And(preds ...Predicate) Predicate {
+	return func(d *DirEntry) bool {
+		for _, pred := range preds {
+			if !pred(d) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// Or returns a Predicate satisfied if any of preds is satisfied.
+// The preds are called in order until one is satisfied.
+func Or(preds ...Predicate) Predicate {
+	return func(d *DirEntry) bool {
+		for _, pred := range preds {
+			if pred(d) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not returns a Predicate satisfied if pred is not satisfied.
+func Not(pred Predicate) Predicate {
+	return func(d *DirEntry) bool {
+		return !pred(d)
+	}
+}
+
+// infoPredicate returns a Predicate calling f with the FileInfo of the entry.
+func infoPredicate(f func(info fs.FileInfo) bool) Predicate {
+	return func(d *DirEntry) bool {
+		info, err := d.Info()
+		return err == nil && f(info)
+	}
+}
+
+// SizeAbove returns a Predicate satisfied by the entries larger than size bytes.
+func SizeAbove(size int64) Predicate {
+	return infoPredicate(func(info fs.FileInfo) bool {
+		return info.Size() > size
+	})
+}
+
+// ModifiedSince returns a Predicate satisfied by the entries modified at or after t.
+func ModifiedSince(t time.Time) Predicate {
+	return infoPredicate(func(info fs.FileInfo) bool {
+		return !info.ModTime().Before(t)
+	})
+}
+
+// Mode returns a Predicate satisfied by the entries whose mode has all the bits of mask set,
+// such as fs.ModeDir and 0o111.
+func Mode(mask fs.FileMode) Predicate {
+	return infoPredicate(func(info fs.FileInfo) bool {
+		return info.Mode()&mask == mask
+	})
+}
+
+// Ext returns a Predicate satisfied by the entries whose name extensions are any of exts,
+// such as ".go". See [path.Ext].
+func Ext(exts ...string) Predicate {
+	return func(d *DirEntry) bool {
+		return slices.Contains(exts, path.Ext(d.Name()))
+	}
+}
+
+// NameMatch returns a Predicate satisfied by the entries whose names match pattern, using the syntax of [path.Match].
+// NameMatch panics if pattern is malformed.
+func NameMatch(pattern string) Predicate {
+	if _, err := path.Match(pattern, ""); err != nil {
+		panic(err)
+	}
+	return func(d *DirEntry) bool {
+		ok, _ := path.Match(pattern, d.Name())
+		return ok
+	}
+}
@@ -0,0 +1,87 @@
+package iter2
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+var findTime = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+var findFS = fstest.MapFS{
+	"a.go":           {Data: make([]byte, 10), ModTime: findTime},
+	"b.txt":          {Data: make([]byte, 100), ModTime: findTime.Add(time.Hour)},
+	"run.sh":         {Data: make([]byte, 50), Mode: 0o755, ModTime: findTime.Add(-time.Hour)},
+	"sub/c.go":       {Data: make([]byte, 200), ModTime: findTime.Add(2 * time.Hour)},
+	"sub/d.md":       {Data: make([]byte, 1), ModTime: findTime},
+	"vendor/x/e.go":  {Data: make([]byte, 300), ModTime: findTime},
+	"vendor/x/f.txt": {Data: make([]byte, 300), ModTime: findTime},
+}
+
+func findPaths(t *testing.T, pred Predicate) (s []string) {
+	return walkPaths(t, Find(WalkDir(findFS, "."), pred))
+}
+
+func TestFind(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		pred Predicate
+		want []string
+	}{
+		{"Ext", Ext(".go", ".md"), []string{"a.go", "sub/c.go", "sub/d.md", "vendor/x/e.go"}},
+		{"SizeAbove", SizeAbove(100), []string{"sub/c.go", "vendor/x/e.go", "vendor/x/f.txt"}},
+		{"ModifiedSince", And(ModifiedSince(findTime.Add(time.Hour)), Not(Mode(fs.ModeDir))), []string{"b.txt", "sub/c.go"}},
+		{"Mode", And(Mode(0o100), Not(Mode(fs.ModeDir))), []string{"run.sh"}},
+		{"NameMatch", NameMatch("[ab].*"), []string{"a.go", "b.txt"}},
+		{"Or", Or(Ext(".md"), NameMatch("*.sh")), []string{"run.sh", "sub/d.md"}},
+		{"Prune", And(Prune(Not(NameMatch("vendor"))), Ext(".go")), []string{"a.go", "sub/c.go"}},
+	} {
+		if s := findPaths(t, c.pred); !slices.Equal(s, c.want) {
+			t.Fatal(c.name, s)
+		}
+	}
+}
+
+func TestFindInfoCached(t *testing.T) {
+	for d, err := range WalkDir(findFS, ".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		var calls int
+		d.Entry = countInfoEntry{d.Entry, &calls}
+		And(SizeAbove(-1), ModifiedSince(time.Time{}), Mode(0))(d)
+		if calls != 1 {
+			t.Fatal(d.Path, calls)
+		}
+	}
+}
+
+func TestFindErr(t *testing.T) {
+	var errs []error
+	for _, err := range Find(WalkDir(findFS, "NO THIS FILE"), Ext(".go")) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 {
+		t.Fatal(errs)
+	}
+	// The root without Entry satisfies no FileInfo predicate.
+	if SizeAbove(-1)(&DirEntry{Path: "x"}) {
+		t.Fatal("should not be satisfied")
+	}
+}
+
+func TestNameMatchBadPattern(t *testing.T) {
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		NameMatch("[a-")
+	}()
+	if panicked != path.ErrBadPattern {
+		t.Fatal(panicked)
+	}
+}
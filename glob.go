@@ -0,0 +1,150 @@
+package iter2
+
+import (
+	"io/fs"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+)
+
+// scanPattern calls f with the index of each byte of pattern that is not escaped by backslash
+// or in a character class, until f returns false.
+func scanPattern(pattern string, f func(i int) bool) {
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '\\':
+			i++
+		case '[':
+			// Skip the character class. A malformed one is reported by path.Match.
+			for i++; i < len(pattern) && pattern[i] != ']'; i++ {
+				if pattern[i] == '\\' {
+					i++
+				}
+			}
+		default:
+			if !f(i) {
+				return
+			}
+		}
+	}
+}
+
+// expandBraces returns the patterns expanded from the brace expressions in pattern.
+// For example, "a{b,c{d,e}}" expands to "ab", "acd" and "ace".
+func expandBraces(pattern string) ([]string, error) {
+	lbrace, rbrace := -1, -1
+	var commas []int // top level commas in the braces
+	depth := 0
+	scanPattern(pattern, func(i int) bool {
+		switch pattern[i] {
+		case '{':
+			if depth == 0 {
+				lbrace = i
+			}
+			depth++
+		case '}':
+			if depth == 0 {
+				return true // literal
+			}
+			if depth--; depth == 0 {
+				rbrace = i
+				return false
+			}
+		case ',':
+			if depth == 1 {
+				commas = append(commas, i)
+			}
+		}
+		return true
+	})
+	if lbrace < 0 {
+		return []string{pattern}, nil
+	}
+	if rbrace < 0 {
+		return nil, path.ErrBadPattern
+	}
+	var patterns []string
+	start := lbrace + 1
+	for _, end := range append(commas, rbrace) {
+		expanded, err := expandBraces(pattern[:lbrace] + pattern[start:end] + pattern[rbrace+1:])
+		if err != nil {
+			return nil, err
+		}
+		patterns = append(patterns, expanded...)
+		start = end + 1
+	}
+	return patterns, nil
+}
+
+// mayMatchIn reports whether the pattern elements pat may match a path in the directory whose
+// path elements are elems.
+func mayMatchIn(pat, elems []string) bool {
+	for ; len(elems) > 0; pat, elems = pat[1:], elems[1:] {
+		if len(pat) == 0 {
+			return false
+		}
+		if pat[0] == "**" {
+			return true
+		}
+		if ok, _ := path.Match(pat[0], elems[0]); !ok {
+			return false
+		}
+	}
+	return len(pat) > 0
+}
+
+// Glob returns an iterator over the names of files in fsys matching any of patterns,
+// in the order of [WalkDir]. Each name is yielded once even if it matches more than one pattern.
+//
+// The patterns are matched against the whole slash-separated names, using the syntax of [path.Match],
+// with the extensions that a "**" path element matches zero or more path elements, and that a brace
+// expression "{a,b}" matches any of the comma-separated alternatives, which can be nested.
+// The root of fsys is never yielded.
+//
+// Glob streams the names while walking, and does not read the directories that can not contain a match.
+// The errors reading fsys are yielded with the names of the entries failed. If any pattern is malformed,
+// Glob yields [path.ErrBadPattern] in a [*WalkError] with Op "match", and walks nothing.
+func Glob(fsys fs.FS, patterns ...string) iter.Seq2[string, error] {
+	return func(yield func(string, error) bool) {
+		var pats [][]string
+		for _, pattern := range patterns {
+			expanded, err := expandBraces(pattern)
+			for _, p := range expanded {
+				if err == nil {
+					err = validPattern(p)
+				}
+			}
+			if err != nil {
+				yield("", &WalkError{Op: "match", Path: pattern, Err: err})
+				return
+			}
+			for _, p := range expanded {
+				pats = append(pats, strings.Split(p, "/"))
+			}
+		}
+		if len(pats) == 0 {
+			return
+		}
+		for d, err := range WalkDir(fsys, ".") {
+			if err != nil {
+				if !yield(d.Path, err) {
+					return
+				}
+				continue
+			}
+			if d.RelPath == "." {
+				continue
+			}
+			elems := strings.Split(d.RelPath, "/")
+			if slices.ContainsFunc(pats, func(pat []string) bool { return matchElems(pat, elems) }) {
+				if !yield(d.Path, nil) {
+					return
+				}
+			}
+			if d.Entry.IsDir() && !slices.ContainsFunc(pats, func(pat []string) bool { return mayMatchIn(pat, elems) }) {
+				d.SkipDir()
+			}
+		}
+	}
+}
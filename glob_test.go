@@ -0,0 +1,107 @@
+package iter2
+
+import (
+	"errors"
+	"io/fs"
+	"path"
+	"slices"
+	"testing"
+	"testing/fstest"
+)
+
+func TestExpandBraces(t *testing.T) {
+	for _, c := range []struct {
+		pattern string
+		want    []string
+	}{
+		{"a", []string{"a"}},
+		{"a{b,c}d", []string{"abd", "acd"}},
+		{"a{b,c{d,e}}", []string{"ab", "acd", "ace"}},
+		{"{a,b}/{c,d}", []string{"a/c", "a/d", "b/c", "b/d"}},
+		{"a{,b}", []string{"a", "ab"}},
+		{`a\{b,c}`, []string{`a\{b,c}`}},
+		{"[{]a}", []string{"[{]a}"}},
+	} {
+		if s, err := expandBraces(c.pattern); err != nil || !slices.Equal(s, c.want) {
+			t.Fatal(c.pattern, s, err)
+		}
+	}
+	if _, err := expandBraces("a{b,c"); err != path.ErrBadPattern {
+		t.Fatal(err)
+	}
+}
+
+// readDirCountFS counts the directories read.
+type readDirCountFS struct {
+	fstest.MapFS
+	read *[]string
+}
+
+func (fsys readDirCountFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	*fsys.read = append(*fsys.read, name)
+	return fsys.MapFS.ReadDir(name)
+}
+
+func globPaths(t *testing.T, fsys fs.FS, patterns ...string) (s []string) {
+	for name, err := range Glob(fsys, patterns...) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, name)
+	}
+	return
+}
+
+func TestGlob(t *testing.T) {
+	for _, c := range []struct {
+		patterns []string
+		want     []string
+	}{
+		{[]string{"*.txt"}, []string{"a.txt"}},
+		{[]string{"**/*.go"}, []string{"dir1/b.go", "dir1/dir2/e.go", "vendor/lib/f.go"}},
+		{[]string{"dir1/**"}, []string{"dir1", "dir1/.c.txt", "dir1/b.go", "dir1/dir2", "dir1/dir2/d.txt", "dir1/dir2/e.go"}},
+		{[]string{"dir1/*/[d-e].*"}, []string{"dir1/dir2/d.txt", "dir1/dir2/e.go"}},
+		{[]string{"{dir1,vendor}/**/*.{go,txt}"}, []string{"dir1/.c.txt", "dir1/b.go", "dir1/dir2/d.txt", "dir1/dir2/e.go", "vendor/lib/f.go", "vendor/lib/g.txt"}},
+		{[]string{"a.txt", "*.txt", "dir3/*/*/h"}, []string{"a.txt", "dir3/dir4/dir5/h"}},
+		{[]string{"no/such/file"}, nil},
+		{nil, nil},
+	} {
+		if s := globPaths(t, walkFS, c.patterns...); !slices.Equal(s, c.want) {
+			t.Fatal(c.patterns, s)
+		}
+	}
+}
+
+func TestGlobPrune(t *testing.T) {
+	var read []string
+	fsys := readDirCountFS{walkFS, &read}
+	if s := globPaths(t, fsys, "dir1/dir2/*.go", "vendor/*"); !slices.Equal(s, []string{"dir1/dir2/e.go", "vendor/lib"}) {
+		t.Fatal(s)
+	}
+	if !slices.Equal(read, []string{".", "dir1", "dir1/dir2", "vendor"}) {
+		t.Fatal(read)
+	}
+
+	// early stop
+	read = nil
+	for range Glob(fsys, "**") {
+		break
+	}
+	if !slices.Equal(read, []string{"."}) {
+		t.Fatal(read)
+	}
+}
+
+func TestGlobBadPattern(t *testing.T) {
+	for _, pattern := range []string{"a/[b-", "{a,b", "{a,[}"} {
+		var errs []error
+		for _, err := range Glob(walkFS, "*.go", pattern) {
+			errs = append(errs, err)
+		}
+		var walkErr *WalkError
+		if len(errs) != 1 || !errors.Is(errs[0], path.ErrBadPattern) ||
+			!errors.As(errs[0], &walkErr) || walkErr.Op != "match" || walkErr.Path != pattern {
+			t.Fatal(pattern, errs)
+		}
+	}
+}
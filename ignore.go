@@ -0,0 +1,96 @@
+package iter2
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+)
+
+// ignoreRule is a pattern of an ignore file, such as .gitignore.
+type ignoreRule struct {
+	elems   []string // slash-separated elements of the pattern
+	base    bool     // whether the pattern matches the last element of paths, at any depth
+	negate  bool     // whether the pattern re-includes paths
+	dirOnly bool     // whether the pattern only matches directories
+}
+
+// parseIgnore parses the content of an ignore file with the syntax of .gitignore.
+// Malformed patterns are ignored.
+func parseIgnore(data []byte) (rules []ignoreRule) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if trimmed := strings.TrimRight(line, " "); strings.HasSuffix(trimmed, `\`) && len(trimmed) < len(line) {
+			line = trimmed + " " // escaped trailing space
+		} else {
+			line = trimmed
+		}
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		var rule ignoreRule
+		if line[0] == '!' {
+			rule.negate = true
+			line = line[1:]
+		} else if line[0] == '\\' && len(line) > 1 && (line[1] == '#' || line[1] == '!') {
+			line = line[1:]
+		}
+		if strings.HasSuffix(line, "/") {
+			rule.dirOnly = true
+			line = strings.TrimRight(line, "/")
+		}
+		if line == "" {
+			continue
+		}
+		rule.base = !strings.Contains(line, "/")
+		line = strings.ReplaceAll(strings.TrimPrefix(line, "/"), "[!", "[^")
+		if validPattern(line) != nil {
+			continue
+		}
+		rule.elems = strings.Split(line, "/")
+		if n := len(rule.elems); n > 1 && rule.elems[n-1] == "**" {
+			// "dir/**" matches everything inside dir, but not dir itself.
+			rule.elems = append(rule.elems[:n-1], "*", "**")
+		}
+		rules = append(rules, rule)
+	}
+	return
+}
+
+// match reports whether the path name, relative to the directory of the ignore file, matches r.
+func (r *ignoreRule) match(name string, isDir bool) bool {
+	if r.dirOnly && !isDir {
+		return false
+	}
+	if r.base {
+		ok, _ := path.Match(r.elems[0], path.Base(name))
+		return ok
+	}
+	return matchElems(r.elems, strings.Split(name, "/"))
+}
+
+// ignoreRules is the rules of the ignore files read, keyed by the paths of their directories
+// relative to the root of the walk.
+type ignoreRules map[string][]ignoreRule
+
+// ignored reports whether the path rel, relative to the root of the walk, is ignored.
+// The rules of deeper directories take precedence, and the last matching rule in the same
+// directory takes precedence.
+func (m ignoreRules) ignored(rel string, isDir bool) (ignored bool) {
+	elems := strings.Split(rel, "/")
+	for i := range elems {
+		dir := "."
+		if i > 0 {
+			dir = strings.Join(elems[:i], "/")
+		}
+		rules := m[dir]
+		name := strings.Join(elems[i:], "/")
+		for j := range rules {
+			if rules[j].match(name, isDir) {
+				ignored = !rules[j].negate
+			}
+		}
+	}
+	return
+}
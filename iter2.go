@@ -1,33 +1,43 @@
 package iter2
 
 import (
+	"errors"
 	"io/fs"
 	"iter"
 	"slices"
 	"sync"
 )
 
+// zip pulls values from seq1 and seq2 in lockstep and calls f with them until f returns false.
+// If longest is false, zip stops as soon as either of seq1 or seq2 stops, without pulling seq2
+// if seq1 has already stopped. Otherwise, zip stops when both seq1 and seq2 stop.
+func zip[T1, T2 any](seq1 iter.Seq[T1], seq2 iter.Seq[T2], longest bool, f func(v1 T1, ok1 bool, v2 T2, ok2 bool) bool) {
+	next1, stop1 := iter.Pull(seq1)
+	defer stop1()
+	next2, stop2 := iter.Pull(seq2)
+	defer stop2()
+	for {
+		v1, ok1 := next1()
+		if !ok1 && !longest {
+			return
+		}
+		v2, ok2 := next2()
+		if !ok2 && (!longest || !ok1) {
+			return
+		}
+		if !f(v1, ok1, v2, ok2) {
+			return
+		}
+	}
+}
+
 // Zip returns an iter.Seq that pairs corresponding elements from iter1 and iter2.
 // Iteration stops when either of the seq1 or seq2 stops.
 func Zip[T1, T2, Pair any](seq1 iter.Seq[T1], seq2 iter.Seq[T2], pair func(v1 T1, v2 T2) Pair) iter.Seq[Pair] {
 	return func(yield func(Pair) bool) {
-		next1, stop1 := iter.Pull(seq1)
-		defer stop1()
-		next2, stop2 := iter.Pull(seq2)
-		defer stop2()
-		for {
-			v1, ok1 := next1()
-			if !ok1 {
-				return
-			}
-			v2, ok2 := next2()
-			if !ok2 {
-				return
-			}
-			if !yield(pair(v1, v2)) {
-				return
-			}
-		}
+		zip(seq1, seq2, false, func(v1 T1, _ bool, v2 T2, _ bool) bool {
+			return yield(pair(v1, v2))
+		})
 	}
 }
 
@@ -35,20 +45,75 @@ func Zip[T1, T2, Pair any](seq1 iter.Seq[T1], seq2 iter.Seq[T2], pair func(v1 T1
 // Iteration stops when either of the seq1 or seq2 stops.
 func Zip2[T1, T2 any](seq1 iter.Seq[T1], seq2 iter.Seq[T2]) iter.Seq2[T1, T2] {
 	return func(yield func(T1, T2) bool) {
-		next1, stop1 := iter.Pull(seq1)
-		defer stop1()
-		next2, stop2 := iter.Pull(seq2)
-		defer stop2()
-		for {
-			v1, ok1 := next1()
-			if !ok1 {
-				return
+		zip(seq1, seq2, false, func(v1 T1, _ bool, v2 T2, _ bool) bool {
+			return yield(v1, v2)
+		})
+	}
+}
+
+// ZipLongest returns an iter.Seq2 that pairs corresponding elements from iter1 and iter2.
+// Iteration stops when both seq1 and seq2 stop. After one of them stops, its values
+// are yielded as missing Options.
+func ZipLongest[T1, T2 any](seq1 iter.Seq[T1], seq2 iter.Seq[T2]) iter.Seq2[Option[T1], Option[T2]] {
+	return func(yield func(Option[T1], Option[T2]) bool) {
+		zip(seq1, seq2, true, func(v1 T1, ok1 bool, v2 T2, ok2 bool) bool {
+			return yield(Option[T1]{v1, ok1}, Option[T2]{v2, ok2})
+		})
+	}
+}
+
+// ErrLengthMismatch is the panic value of the iterators returned by [ZipStrict]
+// when the zipped sequences have different lengths.
+var ErrLengthMismatch = errors.New("sequences have different lengths")
+
+// ZipStrict is like [Zip2], but panics with [ErrLengthMismatch] if one of seq1 and seq2
+// stops before the other.
+func ZipStrict[T1, T2 any](seq1 iter.Seq[T1], seq2 iter.Seq[T2]) iter.Seq2[T1, T2] {
+	return func(yield func(T1, T2) bool) {
+		zip(seq1, seq2, true, func(v1 T1, ok1 bool, v2 T2, ok2 bool) bool {
+			if ok1 != ok2 {
+				panic(ErrLengthMismatch)
 			}
-			v2, ok2 := next2()
-			if !ok2 {
-				return
+			return yield(v1, v2)
+		})
+	}
+}
+
+// Zip3 returns an iter.Seq that combines corresponding elements from seq1, seq2 and seq3.
+// Iteration stops when any of the seq1, seq2 or seq3 stops.
+func Zip3[T1, T2, T3, Tuple any](seq1 iter.Seq[T1], seq2 iter.Seq[T2], seq3 iter.Seq[T3], tuple func(v1 T1, v2 T2, v3 T3) Tuple) iter.Seq[Tuple] {
+	type pair struct {
+		v1 T1
+		v2 T2
+	}
+	return Zip(Zip(seq1, seq2, func(v1 T1, v2 T2) pair { return pair{v1, v2} }), seq3,
+		func(p pair, v3 T3) Tuple { return tuple(p.v1, p.v2, v3) })
+}
+
+// ZipN returns an iter.Seq that yields slices of corresponding elements from seqs.
+// Each yielded slice is newly allocated and has the length of len(seqs).
+// Iteration stops when any of the seqs stops.
+func ZipN[T any](seqs ...iter.Seq[T]) iter.Seq[[]T] {
+	if len(seqs) == 0 {
+		return func(yield func([]T) bool) {}
+	}
+	return func(yield func([]T) bool) {
+		nexts := make([]func() (T, bool), len(seqs))
+		for i, seq := range seqs {
+			next, stop := iter.Pull(seq)
+			defer stop()
+			nexts[i] = next
+		}
+		for {
+			row := make([]T, len(nexts))
+			for i, next := range nexts {
+				v, ok := next()
+				if !ok {
+					return
+				}
+				row[i] = v
 			}
-			if !yield(v1, v2) {
+			if !yield(row) {
 				return
 			}
 		}
@@ -69,25 +134,52 @@ func Concat[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
 	}
 }
 
+// Concat2 returns the concation of seqs.
+// Concat2 works the same way as [Concat], except for the type parameters.
+func Concat2[K, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	return func(yield func(K, V) bool) {
+		for _, seq := range seqs {
+			for k, v := range seq {
+				if !yield(k, v) {
+					return
+				}
+			}
+		}
+	}
+}
+
 // Merge combines seqs into one by merging their values.
 // Merge may interleave the values yield by the merged Seq.
 // A similar func [Concat] does not interleave values, but
 // yields all of each source Seq's values in turn before beginning
 // to yield values from the next source Seq.
+// If any of seqs panics, even while being stopped early, the other seqs are stopped,
+// and the panic is re-raised on the consuming goroutine as a [*PanicError].
 func Merge[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
 	var n = len(seqs)
 	if n == 0 {
 		return func(yield func(T) bool) {}
 	}
 	return func(yield func(T) bool) {
-		doneR := make(chan struct{}) // done reading
-		doneW := make(chan struct{}) // done writing
+		doneR := make(chan struct{})  // done reading
+		doneW := make(chan struct{})  // done writing
+		failed := make(chan struct{}) // one of seqs panicked
+		var failOnce sync.Once
+		var panicErr *PanicError
 		ch := make(chan T)
 		wg := &sync.WaitGroup{}
 		wg.Add(n)
 		for _, seq := range seqs {
 			go func() {
 				defer wg.Done()
+				defer func() {
+					if v := recover(); v != nil {
+						failOnce.Do(func() {
+							panicErr = newPanicError(v)
+							close(failed)
+						})
+					}
+				}()
 				for v := range seq {
 					select {
 					case ch <- v:
@@ -105,18 +197,73 @@ func Merge[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
 		for {
 			select {
 			case <-doneW:
-				return
+				select {
+				case <-failed:
+					panic(panicErr)
+				default:
+					return
+				}
+			case <-failed:
+				close(doneR)
+				wg.Wait()
+				panic(panicErr)
 			case v := <-ch:
 				if !yield(v) {
 					// early stop
 					close(doneR)
 					wg.Wait()
+					select {
+					case <-failed:
+						panic(panicErr) // panicked while stopping
+					default:
+						return
+					}
+				}
+			}
+		}
+	}
+
+}
+
+// Merge2 combines seqs into one by merging their key-value pairs.
+// Merge2 works the same way as [Merge], except for the type parameters.
+func Merge2[K, V any](seqs ...iter.Seq2[K, V]) iter.Seq2[K, V] {
+	type pair struct {
+		K K
+		V V
+	}
+	paired := make([]iter.Seq[pair], len(seqs))
+	for i, seq := range seqs {
+		paired[i] = func(yield func(pair) bool) {
+			for k, v := range seq {
+				if !yield(pair{k, v}) {
 					return
 				}
 			}
 		}
 	}
+	return func(yield func(K, V) bool) {
+		for p := range Merge(paired...) {
+			if !yield(p.K, p.V) {
+				return
+			}
+		}
+	}
+}
 
+// MergeTagged is like [Merge], but yields each value with the index of its source Seq in seqs.
+func MergeTagged[T any](seqs ...iter.Seq[T]) iter.Seq2[int, T] {
+	tagged := make([]iter.Seq2[int, T], len(seqs))
+	for i, seq := range seqs {
+		tagged[i] = func(yield func(int, T) bool) {
+			for v := range seq {
+				if !yield(i, v) {
+					return
+				}
+			}
+		}
+	}
+	return Merge2(tagged...)
 }
 
 // Map returns an iter.Seq that contains a sequence transformed form seq by func f.
@@ -219,14 +366,25 @@ func Take2[K, V any](seq2 iter.Seq2[K, V], n int) iter.Seq2[K, V] {
 }
 
 // DirEntry is a file or directory of a file tree.
+// DirEntry is not safe for concurrent use.
 type DirEntry struct {
 	// Path contains the argument to WalkDir as a prefix. That is, if WalkDir is called with root argument "dir"
 	// and finds a file named "a" in that directory, the Path of yielded DirEntry is "dir/a".
 	Path string
+	// RelPath is the slash-separated path relative to the root. The RelPath of the root is ".".
+	RelPath string
 	// Entry is the [fs.DirEntry] for the named path.
+	// Entry is nil only if the DirEntry of the root is yielded with an error that prevents the whole walk,
+	// in which case nothing else is yielded. The error is a [*WalkError] with Op "stat" if the root can not
+	// be stat'ed, such as it does not exist, or with Op "match" if a pattern of [WalkDirWith] is malformed.
 	Entry fs.DirEntry
+	// Depth is the number of path elements between the root and the named path.
+	// The root has depth 0, the entries in the root directory have depth 1, and so on.
+	Depth int
 
-	err error
+	err     error
+	info    fs.FileInfo // cached result of Info
+	infoErr error
 }
 
 // SkipDir skips the current directory (path if d.IsDir() is true, otherwise path's parent directory).
@@ -240,17 +398,14 @@ func (dir *DirEntry) SkipAll() {
 }
 
 // WalkDir returns an iterator over the file tree rooted at root.
+// The errors yielded are [*WalkError]s. Op "stat" is the error of the root, and the DirEntry is yielded
+// with nil Entry. Op "readdir" is the error reading a directory, and the DirEntry of the directory is
+// yielded a second time with the error, after the first time without error. If the DirEntry is not
+// skipped, the entries read before the error are still walked.
+// Because the errors are not *fs.PathError, [os.IsNotExist] and [os.IsPermission] report false for them.
+// Use errors.Is(err, fs.ErrNotExist) and errors.Is(err, fs.ErrPermission) instead.
 func WalkDir(fsys fs.FS, root string) iter.Seq2[*DirEntry, error] {
-	return func(yield func(*DirEntry, error) bool) {
-		fs.WalkDir(fsys, root, func(path string, d fs.DirEntry, err error) error {
-			dir := &DirEntry{Path: path, Entry: d}
-			if !yield(dir, err) {
-				dir.err = fs.SkipAll // early stop. skip all.
-			}
-			return dir.err
-		})
-	}
-
+	return WalkDirWith(fsys, root, nil)
 }
 
 // Push creates an iterator whose values are yielded by function calls.
@@ -258,6 +413,7 @@ func WalkDir(fsys fs.FS, root string) iter.Seq2[*DirEntry, error] {
 // Stop ends the iteration. It must be called when the caller has no next value to push.
 // It is valid to call stop multiple times. Typically, callers should “defer stop()”.
 // It is safe to call yield and stop from multiple goroutines simultaneously.
+// See [RelayPanic] for re-raising the panics of a producer goroutine on the consuming goroutine.
 //
 // Push is useful when yielding values out of a loop.
 func Push[T any]() (seq iter.Seq[T], yield func(T) bool, stop func()) {
@@ -40,6 +40,31 @@ func ExampleZip2() {
 	// 3 three
 }
 
+func ExampleZipLongest() {
+	ks := []int{1, 2, 3}
+	vs := []string{"one", "two"}
+	for k, v := range iter2.ZipLongest(slices.Values(ks), slices.Values(vs)) {
+		fmt.Println(k.V, v.V, v.Valid)
+	}
+	// Output:
+	// 1 one true
+	// 2 two true
+	// 3  false
+}
+
+func ExampleZipN() {
+	seq1 := slices.Values([]int{1, 2, 3})
+	seq2 := slices.Values([]int{4, 5, 6})
+	seq3 := slices.Values([]int{7, 8, 9})
+	for row := range iter2.ZipN(seq1, seq2, seq3) {
+		fmt.Println(row)
+	}
+	// Output:
+	// [1 4 7]
+	// [2 5 8]
+	// [3 6 9]
+}
+
 func ExampleConcat() {
 	seq1 := slices.Values([]int{1, 2, 3})
 	seq2 := slices.Values([]int{4, 5})
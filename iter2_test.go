@@ -1,6 +1,8 @@
 package iter2
 
 import (
+	"errors"
+	"io/fs"
 	"maps"
 	"os"
 	"slices"
@@ -34,6 +36,85 @@ func TestZip2(t *testing.T) {
 	}
 }
 
+func TestZipLongest(t *testing.T) {
+	type pair struct {
+		N   Option[int]
+		Str Option[string]
+	}
+	var s []pair
+	for n, str := range ZipLongest(slices.Values([]int{1, 2, 3}), slices.Values([]string{"one"})) {
+		s = append(s, pair{n, str})
+	}
+	if !slices.Equal(s, []pair{{Some(1), Some("one")}, {Some(2), Option[string]{}}, {Some(3), Option[string]{}}}) {
+		t.Fatal(s)
+	}
+
+	s = nil
+	for n, str := range ZipLongest(Empty[int], slices.Values([]string{"one", "two"})) {
+		s = append(s, pair{n, str})
+	}
+	if !slices.Equal(s, []pair{{Option[int]{}, Some("one")}, {Option[int]{}, Some("two")}}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if m := maps.Collect(Take2(ZipLongest(slices.Values([]int{1, 2, 3}), Empty[int]), 1)); len(m) != 1 {
+		t.Fatal(m)
+	}
+}
+
+func TestZipStrict(t *testing.T) {
+	ks := []int{1, 2, 3}
+	vs := []string{"one", "two", "three"}
+	m := maps.Collect(ZipStrict(slices.Values(ks), slices.Values(vs)))
+	if !maps.Equal(m, map[int]string{1: "one", 2: "two", 3: "three"}) {
+		t.Fatal(m)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range ZipStrict(slices.Values(ks), slices.Values(vs[:2])) {
+		}
+	}()
+	if panicked != ErrLengthMismatch {
+		t.Fatal(panicked)
+	}
+
+	// early stop does not panic
+	m = maps.Collect(Take2(ZipStrict(slices.Values(ks), slices.Values(vs[:2])), 2))
+	if !maps.Equal(m, map[int]string{1: "one", 2: "two"}) {
+		t.Fatal(m)
+	}
+}
+
+func TestZip3(t *testing.T) {
+	s := slices.Collect(Zip3(slices.Values([]int{1, 2, 3}), slices.Values([]string{"a", "b"}), slices.Values([]bool{true, false, true}),
+		func(n int, s string, b bool) string { return strconv.Itoa(n) + s + strconv.FormatBool(b) }))
+	if !slices.Equal(s, []string{"1atrue", "2bfalse"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestZipN(t *testing.T) {
+	s := slices.Collect(ZipN(slices.Values([]int{1, 2, 3}), slices.Values([]int{4, 5, 6}), slices.Values([]int{7, 8})))
+	if !slices.EqualFunc(s, [][]int{{1, 4, 7}, {2, 5, 8}}, slices.Equal) {
+		t.Fatal(s)
+	}
+
+	if s := slices.Collect(ZipN[int]()); len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	// early stop
+	s = slices.Collect(Take(ZipN(slices.Values([]int{1, 2, 3})), 1))
+	if !slices.EqualFunc(s, [][]int{{1}}, slices.Equal) {
+		t.Fatal(s)
+	}
+}
+
 func TestConcat(t *testing.T) {
 	seq1 := slices.Values([]int{1, 2, 3})
 	seq2 := slices.Values([]int{4, 5})
@@ -52,6 +133,27 @@ func TestConcat(t *testing.T) {
 	}
 }
 
+func TestConcat2(t *testing.T) {
+	seq1 := slices.All([]string{"a", "b"})
+	seq2 := maps.All(map[int]string{10: "c"})
+	type pair struct {
+		K int
+		V string
+	}
+	var s []pair
+	for k, v := range Concat2(seq1, seq2) {
+		s = append(s, pair{k, v})
+	}
+	if !slices.Equal(s, []pair{{0, "a"}, {1, "b"}, {10, "c"}}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if m := maps.Collect(Take2(Concat2(seq1, seq2), 1)); !maps.Equal(m, map[int]string{0: "a"}) {
+		t.Fatal(m)
+	}
+}
+
 func TestMerge(t *testing.T) {
 	var seq1 = func(yield func(int) bool) {
 		ticker := time.NewTicker(time.Millisecond * 20)
@@ -100,6 +202,29 @@ func TestMergeSlice(t *testing.T) {
 	}
 }
 
+func TestMerge2(t *testing.T) {
+	seq := Merge2(slices.All([]string{"a", "b"}), maps.All(map[int]string{10: "c"}))
+	if m := maps.Collect(seq); !maps.Equal(m, map[int]string{0: "a", 1: "b", 10: "c"}) {
+		t.Fatal(m)
+	}
+
+	// early stop
+	if m := maps.Collect(Take2(seq, 2)); len(m) != 2 {
+		t.Fatal(m)
+	}
+}
+
+func TestMergeTagged(t *testing.T) {
+	seq := MergeTagged(slices.Values([]int{1, 2, 3}), slices.Values([]int{4, 5}))
+	m := make(map[int][]int)
+	for i, v := range seq {
+		m[i] = append(m[i], v)
+	}
+	if !maps.EqualFunc(m, map[int][]int{0: {1, 2, 3}, 1: {4, 5}}, slices.Equal) {
+		t.Fatal(m)
+	}
+}
+
 func TestMap(t *testing.T) {
 	seq := Map(slices.Values([]int{1, 2, 3}), func(v int) string { return strconv.Itoa(v + 1) })
 	if s := slices.Collect(seq); !slices.Equal(s, []string{"2", "3", "4"}) {
@@ -325,9 +450,19 @@ func TestWalkDirErr(t *testing.T) {
 	if !slices.Equal(s, []string{}) {
 		t.Fatal(s)
 	}
-	if err == nil || !os.IsNotExist(err) {
+	if err == nil || !errors.Is(err, fs.ErrNotExist) {
 		t.Fatal("should be not exist")
 	}
+	var walkErr *WalkError
+	if !errors.As(err, &walkErr) || walkErr.Op != "stat" || walkErr.Path != "NO THIS FILE" {
+		t.Fatal(err)
+	}
+	if d.Entry != nil || d.RelPath != "." || d.Name() != "NO THIS FILE" {
+		t.Fatal(d)
+	}
+	if _, err := d.Info(); err == nil {
+		t.Fatal("should fail")
+	}
 }
 
 func TestPush(t *testing.T) {
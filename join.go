@@ -0,0 +1,137 @@
+package iter2
+
+import (
+	"cmp"
+	"iter"
+)
+
+// hashBy reads all values of seq into a map keyed by key.
+// Values with the same key are kept in the order they were yielded.
+func hashBy[T any, K comparable](seq iter.Seq[T], key func(T) K) map[K][]T {
+	m := make(map[K][]T)
+	for v := range seq {
+		k := key(v)
+		m[k] = append(m[k], v)
+	}
+	return m
+}
+
+// Join returns an iterator over pairs of values from left and right whose keys are equal.
+// Right is read into an in-memory hash table when the iteration starts, left is streamed.
+// Pairs are yielded in the order of left, and then in the order of right for the same left value.
+func Join[L, R any, K comparable](left iter.Seq[L], right iter.Seq[R], leftKey func(L) K, rightKey func(R) K) iter.Seq2[L, R] {
+	return func(yield func(L, R) bool) {
+		m := hashBy(right, rightKey)
+		for l := range left {
+			for _, r := range m[leftKey(l)] {
+				if !yield(l, r) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// LeftJoin is like [Join], but also yields the values of left that have no match in right,
+// paired with a missing Option.
+func LeftJoin[L, R any, K comparable](left iter.Seq[L], right iter.Seq[R], leftKey func(L) K, rightKey func(R) K) iter.Seq2[L, Option[R]] {
+	return func(yield func(L, Option[R]) bool) {
+		m := hashBy(right, rightKey)
+		for l := range left {
+			rs := m[leftKey(l)]
+			if len(rs) == 0 {
+				if !yield(l, Option[R]{}) {
+					return
+				}
+				continue
+			}
+			for _, r := range rs {
+				if !yield(l, Some(r)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// FullOuterJoin is like [LeftJoin], but also yields the values of right that have no match in left,
+// paired with a missing Option. The unmatched values of right are yielded last, in the order of right.
+func FullOuterJoin[L, R any, K comparable](left iter.Seq[L], right iter.Seq[R], leftKey func(L) K, rightKey func(R) K) iter.Seq2[Option[L], Option[R]] {
+	return func(yield func(Option[L], Option[R]) bool) {
+		var keys []K // keys of right in order of first appearance
+		m := make(map[K][]R)
+		for r := range right {
+			k := rightKey(r)
+			if _, ok := m[k]; !ok {
+				keys = append(keys, k)
+			}
+			m[k] = append(m[k], r)
+		}
+		matched := make(map[K]bool)
+		for l := range left {
+			k := leftKey(l)
+			rs := m[k]
+			if len(rs) == 0 {
+				if !yield(Some(l), Option[R]{}) {
+					return
+				}
+				continue
+			}
+			matched[k] = true
+			for _, r := range rs {
+				if !yield(Some(l), Some(r)) {
+					return
+				}
+			}
+		}
+		for _, k := range keys {
+			if matched[k] {
+				continue
+			}
+			for _, r := range m[k] {
+				if !yield(Option[L]{}, Some(r)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// MergeJoin returns an iterator over pairs of values from left and right whose keys are equal.
+// Both left and right must be sorted by key in ascending order. Unlike [Join], MergeJoin streams
+// both sequences and only buffers the values of right sharing the current key.
+// Pairs are yielded in the order of left, and then in the order of right for the same left value.
+func MergeJoin[L, R any, K cmp.Ordered](left iter.Seq[L], right iter.Seq[R], leftKey func(L) K, rightKey func(R) K) iter.Seq2[L, R] {
+	return func(yield func(L, R) bool) {
+		nextL, stopL := iter.Pull(left)
+		defer stopL()
+		nextR, stopR := iter.Pull(right)
+		defer stopR()
+
+		var run []R // values of right with key runKey
+		var runKey K
+		r, okR := nextR()
+		for l, okL := nextL(); okL; l, okL = nextL() {
+			k := leftKey(l)
+			if len(run) == 0 || runKey != k {
+				run = run[:0]
+				for okR && cmp.Less(rightKey(r), k) {
+					r, okR = nextR()
+				}
+				for okR && rightKey(r) == k {
+					run = append(run, r)
+					r, okR = nextR()
+				}
+				if len(run) == 0 && !okR {
+					return // no more match
+				}
+				runKey = k
+			}
+			for _, r := range run {
+				if !yield(l, r) {
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package iter2_test
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mkch/iter2"
+)
+
+func ExampleJoin() {
+	type User struct {
+		ID   int
+		Name string
+	}
+	type Order struct {
+		UserID int
+		Item   string
+	}
+	users := []User{{1, "User1"}, {2, "User2"}}
+	orders := []Order{{1, "book"}, {2, "pen"}, {1, "cup"}}
+
+	seq := iter2.Join(slices.Values(users), slices.Values(orders),
+		func(u User) int { return u.ID },
+		func(o Order) int { return o.UserID })
+	for user, order := range seq {
+		fmt.Println(user.Name, order.Item)
+	}
+	// Output:
+	// User1 book
+	// User1 cup
+	// User2 pen
+}
+
+func ExampleLeftJoin() {
+	ids := []int{1, 2, 3}
+	names := []string{"1:one", "3:three"}
+	seq := iter2.LeftJoin(slices.Values(ids), slices.Values(names),
+		func(id int) int { return id },
+		func(name string) int { return int(name[0] - '0') })
+	for id, name := range seq {
+		if name.Valid {
+			fmt.Println(id, name.V)
+		} else {
+			fmt.Println(id, "missing")
+		}
+	}
+	// Output:
+	// 1 1:one
+	// 2 missing
+	// 3 3:three
+}
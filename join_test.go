@@ -0,0 +1,113 @@
+package iter2
+
+import (
+	"slices"
+	"testing"
+)
+
+type joinPair[L, R any] struct {
+	L L
+	R R
+}
+
+func collectPairs[L, R any](seq func(yield func(L, R) bool)) (s []joinPair[L, R]) {
+	for l, r := range seq {
+		s = append(s, joinPair[L, R]{l, r})
+	}
+	return
+}
+
+type joinUser struct {
+	ID   int
+	Name string
+}
+
+type joinOrder struct {
+	UserID int
+	Item   string
+}
+
+var (
+	joinUsers  = []joinUser{{1, "a"}, {2, "b"}, {3, "c"}}
+	joinOrders = []joinOrder{{1, "x"}, {3, "y"}, {1, "z"}, {4, "w"}}
+)
+
+func userID(u joinUser) int       { return u.ID }
+func orderUserID(o joinOrder) int { return o.UserID }
+
+func TestJoin(t *testing.T) {
+	seq := Join(slices.Values(joinUsers), slices.Values(joinOrders), userID, orderUserID)
+	s := collectPairs(seq)
+	if !slices.Equal(s, []joinPair[joinUser, joinOrder]{
+		{joinUser{1, "a"}, joinOrder{1, "x"}},
+		{joinUser{1, "a"}, joinOrder{1, "z"}},
+		{joinUser{3, "c"}, joinOrder{3, "y"}},
+	}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	s = collectPairs(Take2(seq, 1))
+	if !slices.Equal(s, []joinPair[joinUser, joinOrder]{{joinUser{1, "a"}, joinOrder{1, "x"}}}) {
+		t.Fatal(s)
+	}
+}
+
+func TestLeftJoin(t *testing.T) {
+	seq := LeftJoin(slices.Values(joinUsers), slices.Values(joinOrders), userID, orderUserID)
+	s := collectPairs(seq)
+	if !slices.Equal(s, []joinPair[joinUser, Option[joinOrder]]{
+		{joinUser{1, "a"}, Some(joinOrder{1, "x"})},
+		{joinUser{1, "a"}, Some(joinOrder{1, "z"})},
+		{joinUser{2, "b"}, Option[joinOrder]{}},
+		{joinUser{3, "c"}, Some(joinOrder{3, "y"})},
+	}) {
+		t.Fatal(s)
+	}
+}
+
+func TestFullOuterJoin(t *testing.T) {
+	seq := FullOuterJoin(slices.Values(joinUsers), slices.Values(joinOrders), userID, orderUserID)
+	s := collectPairs(seq)
+	if !slices.Equal(s, []joinPair[Option[joinUser], Option[joinOrder]]{
+		{Some(joinUser{1, "a"}), Some(joinOrder{1, "x"})},
+		{Some(joinUser{1, "a"}), Some(joinOrder{1, "z"})},
+		{Some(joinUser{2, "b"}), Option[joinOrder]{}},
+		{Some(joinUser{3, "c"}), Some(joinOrder{3, "y"})},
+		{Option[joinUser]{}, Some(joinOrder{4, "w"})},
+	}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if s := collectPairs(Take2(seq, 3)); len(s) != 3 {
+		t.Fatal(s)
+	}
+}
+
+func TestMergeJoin(t *testing.T) {
+	left := []int{1, 2, 2, 4, 6, 7}
+	right := []string{"1a", "2a", "2b", "3a", "6a", "6b", "8a"}
+	seq := MergeJoin(slices.Values(left), slices.Values(right),
+		func(n int) int { return n },
+		func(s string) int { return int(s[0] - '0') })
+	s := collectPairs(seq)
+	if !slices.Equal(s, []joinPair[int, string]{
+		{1, "1a"}, {2, "2a"}, {2, "2b"}, {2, "2a"}, {2, "2b"}, {6, "6a"}, {6, "6b"},
+	}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	s = collectPairs(Take2(seq, 2))
+	if !slices.Equal(s, []joinPair[int, string]{{1, "1a"}, {2, "2a"}}) {
+		t.Fatal(s)
+	}
+
+	// empty right
+	if s := collectPairs(MergeJoin(slices.Values(left), Empty[string],
+		func(n int) int { return n },
+		func(s string) int { return int(s[0] - '0') })); len(s) != 0 {
+		t.Fatal(s)
+	}
+}
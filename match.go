@@ -0,0 +1,59 @@
+package iter2
+
+import (
+	"path"
+	"strings"
+)
+
+// validPattern returns path.ErrBadPattern if pattern is malformed.
+func validPattern(pattern string) error {
+	for _, elem := range strings.Split(pattern, "/") {
+		if _, err := path.Match(elem, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// matchPattern reports whether name matches pattern. Name and pattern are slash-separated paths.
+// Besides the syntax of [path.Match], a "**" element of pattern matches zero or more path elements.
+// A pattern without slash matches the last element of name. A leading slash of pattern is ignored.
+func matchPattern(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		ok, _ := path.Match(pattern, path.Base(name))
+		return ok
+	}
+	return matchElems(strings.Split(strings.TrimPrefix(pattern, "/"), "/"), strings.Split(name, "/"))
+}
+
+// matchElems reports whether the path elements elems match the pattern elements pat.
+func matchElems(pat, elems []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			for i := 0; i <= len(elems); i++ {
+				if matchElems(pat[1:], elems[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(elems) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pat[0], elems[0]); !ok {
+			return false
+		}
+		pat, elems = pat[1:], elems[1:]
+	}
+	return len(elems) == 0
+}
+
+// matchAny reports whether name matches any of patterns. See matchPattern.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
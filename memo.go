@@ -0,0 +1,249 @@
+package iter2
+
+import (
+	"bytes"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"os"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// MemoStore stores the values cached by [MemoizeStore].
+// The methods of MemoStore are never called simultaneously by MemoizeStore.
+type MemoStore[T any] interface {
+	// Append appends v to the store.
+	Append(v T) error
+	// At returns the ith value appended.
+	At(i int) (T, error)
+}
+
+// sliceStore is a MemoStore in memory.
+type sliceStore[T any] []T
+
+func (s *sliceStore[T]) Append(v T) error {
+	*s = append(*s, v)
+	return nil
+}
+
+func (s *sliceStore[T]) At(i int) (T, error) {
+	return (*s)[i], nil
+}
+
+// memo is the state shared by the iterations of a memoized iterator.
+type memo[T any] struct {
+	seq   iter.Seq[T]
+	store MemoStore[T]
+
+	mu      sync.Mutex
+	cond    sync.Cond
+	n       int // number of values in store
+	next    func() (T, bool)
+	stop    func()
+	pulling bool // whether next is being called
+	done    bool // whether seq is exhausted or stopped
+}
+
+// get returns the ith value of seq.
+func (m *memo[T]) get(i int) (v T, ok bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for {
+		if i < m.n {
+			v, err := m.store.At(i)
+			if err != nil {
+				panic(err)
+			}
+			return v, true
+		}
+		if m.done {
+			return
+		}
+		if m.pulling {
+			m.cond.Wait()
+			continue
+		}
+		m.pull()
+	}
+}
+
+// pull pulls the next value of seq into the store.
+// m.mu must be held. It is released while pulling.
+func (m *memo[T]) pull() {
+	if m.next == nil {
+		m.next, m.stop = iter.Pull(m.seq)
+	}
+	m.pulling = true
+	m.mu.Unlock()
+	var v T
+	var ok bool
+	defer func() {
+		m.mu.Lock()
+		m.pulling = false
+		m.cond.Broadcast()
+		if !ok {
+			m.done = true // seq is exhausted or panicked
+			return
+		}
+		if err := m.store.Append(v); err != nil {
+			m.done = true
+			panic(err)
+		}
+		m.n++
+	}()
+	v, ok = m.next()
+}
+
+// end stops pulling seq.
+func (m *memo[T]) end() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for m.pulling {
+		m.cond.Wait()
+	}
+	m.done = true
+	if m.stop != nil {
+		m.stop()
+	}
+}
+
+// Memoize returns an iterator that yields the same values as seq, while seq is iterated only once.
+// The values are cached in memory as they are first yielded by seq, and replayed to later iterations.
+// It is safe to iterate memoized multiple times, including from multiple goroutines simultaneously.
+// Stop ends the iteration of seq, after which memoized yields only the cached values. Seq is left suspended
+// until it is exhausted or stop is called, so callers should call stop when memoized is no longer
+// iterated to the end. It is valid to call stop multiple times.
+func Memoize[T any](seq iter.Seq[T]) (memoized iter.Seq[T], stop func()) {
+	return MemoizeStore(seq, &sliceStore[T]{})
+}
+
+// MemoizeStore is like [Memoize], but caches values in store.
+// If store returns an error, the iteration of memoized panics with that error.
+func MemoizeStore[T any](seq iter.Seq[T], store MemoStore[T]) (memoized iter.Seq[T], stop func()) {
+	m := &memo[T]{seq: seq, store: store}
+	m.cond.L = &m.mu
+	memoized = func(yield func(T) bool) {
+		for i := 0; ; i++ {
+			v, ok := m.get(i)
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+	return memoized, m.end
+}
+
+// SpillStore is a [MemoStore] that keeps the first values in memory and spills the rest
+// to a temporary file, encoded with [encoding/gob].
+// The values spilled must be encodable and decodable by gob. For example, a nil pointer and
+// a struct without exported fields can not be spilled, and Append returns an error for them.
+// SpillStore is not safe for concurrent use.
+type SpillStore[T any] struct {
+	limit   int
+	dir     string
+	mem     []T
+	f       *os.File
+	offsets []int64 // offsets of the values in f
+	size    int64   // size of f
+}
+
+// NewSpillStore returns a SpillStore that keeps at most limit values in memory, and spills
+// the rest to a temporary file in dir. If dir is the empty string, the default directory for
+// temporary files is used. See [os.CreateTemp].
+// Callers should call Close when the SpillStore is no longer used.
+func NewSpillStore[T any](limit int, dir string) *SpillStore[T] {
+	return &SpillStore[T]{limit: limit, dir: dir}
+}
+
+// Append implements [MemoStore].
+func (s *SpillStore[T]) Append(v T) (err error) {
+	if len(s.mem) < s.limit {
+		s.mem = append(s.mem, v)
+		return nil
+	}
+	for rv := reflect.ValueOf(&v).Elem(); rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Interface; rv = rv.Elem() {
+		if rv.IsNil() {
+			// gob does not keep nil pointers.
+			return fmt.Errorf("can not spill nil %T with gob", v)
+		}
+	}
+	var buf bytes.Buffer
+	if err = gob.NewEncoder(&buf).Encode(&v); err == nil {
+		// Make sure the value can be decoded by At.
+		var decoded T
+		err = gob.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&decoded)
+	}
+	if err != nil {
+		return fmt.Errorf("can not spill %T with gob: %w", v, err)
+	}
+	if s.f == nil {
+		if s.f, err = os.CreateTemp(s.dir, "iter2-spill-*"); err != nil {
+			return
+		}
+	}
+	n, err := s.f.WriteAt(buf.Bytes(), s.size)
+	if err != nil {
+		return
+	}
+	s.offsets = append(s.offsets, s.size)
+	s.size += int64(n)
+	return
+}
+
+// At implements [MemoStore].
+func (s *SpillStore[T]) At(i int) (v T, err error) {
+	if i < len(s.mem) {
+		return s.mem[i], nil
+	}
+	i -= len(s.mem)
+	if i >= len(s.offsets) {
+		err = errors.New("index out of range")
+		return
+	}
+	end := s.size
+	if i+1 < len(s.offsets) {
+		end = s.offsets[i+1]
+	}
+	r := io.NewSectionReader(s.f, s.offsets[i], end-s.offsets[i])
+	err = gob.NewDecoder(r).Decode(&v)
+	return
+}
+
+// Close removes the temporary file, if any.
+func (s *SpillStore[T]) Close() error {
+	s.mem = nil
+	s.offsets = nil
+	s.size = 0
+	if s.f == nil {
+		return nil
+	}
+	name := s.f.Name()
+	err := s.f.Close()
+	s.f = nil
+	return errors.Join(err, os.Remove(name))
+}
+
+// ErrIteratedTwice is the panic value of the iterators returned by [Once]
+// when they are iterated more than once.
+var ErrIteratedTwice = errors.New("single-use iterator iterated more than once")
+
+// Once returns an iterator that yields the values of seq, but panics with [ErrIteratedTwice]
+// if it is iterated more than once. Once is useful to detect the misuse of single-use iterators,
+// such as the ones returned by [Push] and [MustAllRows].
+func Once[T any](seq iter.Seq[T]) iter.Seq[T] {
+	var used atomic.Bool
+	return func(yield func(T) bool) {
+		if used.Swap(true) {
+			panic(ErrIteratedTwice)
+		}
+		for v := range seq {
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package iter2
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestMemoize(t *testing.T) {
+	seq, yield, stop := Push[int]()
+	go func() {
+		defer stop()
+		for i := range 5 {
+			if !yield(i) {
+				return
+			}
+		}
+	}()
+
+	memo, stopMemo := Memoize(seq)
+	defer stopMemo()
+	if s := slices.Collect(Take(memo, 2)); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(memo); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(memo); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+}
+
+func TestMemoizeConcurrent(t *testing.T) {
+	var pulled int
+	memo, stop := Memoize(func(yield func(int) bool) {
+		for i := range 100 {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	})
+	defer stop()
+
+	results := make([][]int, 5)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = slices.Collect(memo)
+		}()
+	}
+	wg.Wait()
+	for _, s := range results {
+		if len(s) != 100 || s[0] != 0 || s[99] != 99 {
+			t.Fatal(s)
+		}
+	}
+	if pulled != 100 {
+		t.Fatal(pulled)
+	}
+}
+
+func TestMemoizeStop(t *testing.T) {
+	memo, stop := Memoize(slices.Values([]int{1, 2, 3}))
+	if s := slices.Collect(Take(memo, 1)); !slices.Equal(s, []int{1}) {
+		t.Fatal(s)
+	}
+	stop()
+	stop()
+	if s := slices.Collect(memo); !slices.Equal(s, []int{1}) {
+		t.Fatal(s)
+	}
+}
+
+func TestSpillStore(t *testing.T) {
+	type item struct {
+		N   int
+		Str string
+	}
+	store := NewSpillStore[item](2, t.TempDir())
+	defer store.Close()
+	items := []item{{1, "one"}, {2, "two"}, {3, "three"}, {4, "four"}}
+	memo, stop := MemoizeStore(slices.Values(items), store)
+	defer stop()
+	if s := slices.Collect(memo); !slices.Equal(s, items) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(memo); !slices.Equal(s, items) {
+		t.Fatal(s)
+	}
+	if len(store.mem) != 2 || len(store.offsets) != 2 {
+		t.Fatal(store.mem, store.offsets)
+	}
+	if err := store.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSpillStoreNotGob(t *testing.T) {
+	type item struct{ N int }
+	store := NewSpillStore[*item](0, t.TempDir())
+	defer store.Close()
+	if err := store.Append(&item{1}); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Append(nil); err == nil {
+		t.Fatal("nil pointer should not be spilled")
+	}
+	if v, err := store.At(0); err != nil || v.N != 1 || len(store.offsets) != 1 {
+		t.Fatal(v, err)
+	}
+
+	type unexported struct{ n int }
+	store2 := NewSpillStore[unexported](0, t.TempDir())
+	defer store2.Close()
+	if err := store2.Append(unexported{1}); err == nil {
+		t.Fatal("struct without exported fields should not be spilled")
+	}
+}
+
+func TestOnce(t *testing.T) {
+	seq := Once(slices.Values([]int{1, 2}))
+	if s := slices.Collect(seq); !slices.Equal(s, []int{1, 2}) {
+		t.Fatal(s)
+	}
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range seq {
+		}
+	}()
+	if panicked != ErrIteratedTwice {
+		t.Fatal(panicked)
+	}
+}
@@ -0,0 +1,161 @@
+package iter2
+
+import (
+	"iter"
+	"sync"
+)
+
+// slotMerge is the state of an iteration of the iterators returned by [MergePriority] and [MergeWeighted].
+// Each source Seq is iterated in its own goroutine, which puts one value at a time into its slot.
+type slotMerge[T any] struct {
+	mu       sync.Mutex
+	cond     sync.Cond
+	values   []T    // values[i] is the value in the slot of the ith source
+	full     []bool // full[i] is whether the slot of the ith source has a value
+	active   int    // number of sources not yet finished
+	stopped  bool   // whether the consumer has stopped
+	panicErr *PanicError
+	wg       sync.WaitGroup
+}
+
+// produce iterates the ith source.
+func (m *slotMerge[T]) produce(i int, seq iter.Seq[T]) {
+	defer m.wg.Done()
+	defer func() {
+		v := recover()
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if v != nil && m.panicErr == nil {
+			m.panicErr = newPanicError(v)
+		}
+		m.active--
+		m.cond.Broadcast()
+	}()
+	for v := range seq {
+		m.mu.Lock()
+		for m.full[i] && !m.stopped {
+			m.cond.Wait()
+		}
+		if m.stopped {
+			m.mu.Unlock()
+			return
+		}
+		m.values[i] = v
+		m.full[i] = true
+		m.cond.Broadcast()
+		m.mu.Unlock()
+	}
+}
+
+// stop stops the sources and waits for their goroutines to finish.
+func (m *slotMerge[T]) stop() {
+	m.mu.Lock()
+	m.stopped = true
+	m.cond.Broadcast()
+	m.mu.Unlock()
+	m.wg.Wait()
+}
+
+// mergeSlots merges seqs into one. Each time a value is to be yielded, newPick() is called
+// with the slots having values, and returns the index of the slot to yield.
+func mergeSlots[T any](seqs []iter.Seq[T], newPick func() func(full []bool) int) iter.Seq[T] {
+	return func(yield func(T) bool) {
+		pick := newPick()
+		m := &slotMerge[T]{values: make([]T, len(seqs)), full: make([]bool, len(seqs)), active: len(seqs)}
+		m.cond.L = &m.mu
+		m.wg.Add(len(seqs))
+		for i, seq := range seqs {
+			go m.produce(i, seq)
+		}
+		defer m.stop()
+
+		m.mu.Lock()
+		for {
+			if m.panicErr != nil {
+				m.mu.Unlock()
+				m.stop()
+				panic(m.panicErr)
+			}
+			if i := pick(m.full); i >= 0 {
+				v := m.values[i]
+				var zero T
+				m.values[i] = zero
+				m.full[i] = false
+				m.cond.Broadcast()
+				m.mu.Unlock()
+				if !yield(v) {
+					m.stop()
+					if m.panicErr != nil {
+						panic(m.panicErr) // panicked while stopping
+					}
+					return
+				}
+				m.mu.Lock()
+				continue
+			}
+			if m.active == 0 {
+				m.mu.Unlock()
+				return
+			}
+			m.cond.Wait()
+		}
+	}
+}
+
+// MergePriority is like [Merge], but always yields a value from the source Seq with
+// the highest priority among the ones having values ready. Seqs are in descending order
+// of priority, that is, seqs[0] has the highest priority.
+func MergePriority[T any](seqs ...iter.Seq[T]) iter.Seq[T] {
+	if len(seqs) == 0 {
+		return func(yield func(T) bool) {}
+	}
+	return mergeSlots(seqs, func() func(full []bool) int {
+		return func(full []bool) int {
+			for i, ok := range full {
+				if ok {
+					return i
+				}
+			}
+			return -1
+		}
+	})
+}
+
+// MergeWeighted is like [Merge], but yields values from the source Seqs having values ready
+// in proportion to their weights, that is, seqs[i] is served weights[i] times out of the sum
+// of weights when all the source Seqs have values ready.
+// MergeWeighted panics if len(weights) != len(seqs) or any weight is less than 1.
+func MergeWeighted[T any](seqs []iter.Seq[T], weights []int) iter.Seq[T] {
+	if len(weights) != len(seqs) {
+		panic("mismatched weights")
+	}
+	for _, w := range weights {
+		if w < 1 {
+			panic("non-positive weight")
+		}
+	}
+	if len(seqs) == 0 {
+		return func(yield func(T) bool) {}
+	}
+	// Smooth weighted round-robin.
+	return mergeSlots(seqs, func() func(full []bool) int {
+		current := make([]int, len(weights))
+		return func(full []bool) int {
+			selected, total := -1, 0
+			for i, ok := range full {
+				if !ok {
+					continue
+				}
+				current[i] += weights[i]
+				total += weights[i]
+				if selected < 0 || current[i] > current[selected] {
+					selected = i
+				}
+			}
+			if selected >= 0 {
+				current[selected] -= total
+			}
+			return selected
+		}
+	})
+}
@@ -0,0 +1,98 @@
+package iter2
+
+import (
+	"iter"
+	"maps"
+	"slices"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func infiniteSeq(v int) func(yield func(int) bool) {
+	return func(yield func(int) bool) {
+		for yield(v) {
+		}
+	}
+}
+
+func TestMergePriority(t *testing.T) {
+	seq := MergePriority(slices.Values([]int{1, 2, 3}), slices.Values([]int{4, 5}))
+	s := slices.Collect(seq)
+	slices.Sort(s)
+	if !slices.Equal(s, []int{1, 2, 3, 4, 5}) {
+		t.Fatal(s)
+	}
+
+	// slow consumer, both sources are always ready
+	seq = MergePriority(infiniteSeq(1), infiniteSeq(2))
+	var i = 0
+	for v := range Take(seq, 20) {
+		if i > 0 && v != 1 { // the first value may be yielded before seqs[0] is ready
+			t.Fatal(v)
+		}
+		i++
+		time.Sleep(time.Millisecond)
+	}
+
+	if s := slices.Collect(MergePriority[int]()); len(s) != 0 {
+		t.Fatal(s)
+	}
+}
+
+func TestMergeWeighted(t *testing.T) {
+	seq := MergeWeighted([]iter.Seq[int]{infiniteSeq(1), infiniteSeq(2)}, []int{3, 1})
+	counts := make(map[int]int)
+	// slow consumer, both sources are always ready
+	for v := range Take(seq, 40) {
+		counts[v]++
+		time.Sleep(time.Millisecond)
+	}
+	if counts[1] < 25 || counts[2] < 5 {
+		t.Fatal(counts)
+	}
+
+	seq = MergeWeighted([]iter.Seq[int]{slices.Values([]int{1, 2}), slices.Values([]int{3})}, []int{1, 2})
+	m := make(map[int]bool)
+	for v := range seq {
+		m[v] = true
+	}
+	if !maps.Equal(m, map[int]bool{1: true, 2: true, 3: true}) {
+		t.Fatal(m)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		MergeWeighted([]iter.Seq[int]{infiniteSeq(1)}, []int{0})
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestMergeSlotsPanic(t *testing.T) {
+	var stopped atomic.Bool
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range MergePriority(func(yield func(int) bool) {
+			defer stopped.Store(true)
+			for yield(1) {
+			}
+		}, func(yield func(int) bool) {
+			panic("producer")
+		}) {
+		}
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "producer" {
+		t.Fatal(panicked)
+	}
+	if !stopped.Load() {
+		t.Fatal("should be stopped")
+	}
+}
@@ -0,0 +1,18 @@
+package iter2
+
+// Option is a value that may be missing.
+// The zero value of Option is a missing value.
+type Option[T any] struct {
+	V     T
+	Valid bool // Valid is true if V is present.
+}
+
+// Some returns an Option with v present.
+func Some[T any](v T) Option[T] {
+	return Option[T]{V: v, Valid: true}
+}
+
+// Get returns the value and whether it is present.
+func (opt Option[T]) Get() (v T, ok bool) {
+	return opt.V, opt.Valid
+}
@@ -0,0 +1,106 @@
+package iter2
+
+import (
+	"fmt"
+	"iter"
+	"runtime/debug"
+	"sync"
+)
+
+// PanicError is the panic value re-raised on the consuming goroutine
+// when a producer goroutine of an iterator panics.
+type PanicError struct {
+	Value any    // The value passed to panic.
+	Stack []byte // The stack trace of the producer goroutine when it panicked.
+}
+
+func newPanicError(v any) *PanicError {
+	return &PanicError{Value: v, Stack: debug.Stack()}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("%v\n\nproducer goroutine stack:\n%s", e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error, or nil otherwise.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// relay relays a panic of a producer goroutine to the consuming goroutine of an iterator.
+type relay struct {
+	mu        sync.Mutex
+	consuming bool        // whether the consumer is iterating
+	err       *PanicError // the panic to be re-raised by the consumer
+}
+
+// consumerStarted marks the consumer iterating.
+func (r *relay) consumerStarted() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.consuming = true
+}
+
+// producerPanicked records the panic value v recovered from the producer goroutine,
+// or panics with v again if the consumer is not iterating, so that the panic is never lost.
+// It must be called before the consumer is told that the producer is done.
+func (r *relay) producerPanicked(v any) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.consuming {
+		panic(v)
+	}
+	r.err = newPanicError(v)
+}
+
+// consumerFinished marks the consumer not iterating, and re-raises the panic recorded, if any.
+func (r *relay) consumerFinished() {
+	r.mu.Lock()
+	r.consuming = false
+	err := r.err
+	r.err = nil
+	r.mu.Unlock()
+	if err != nil {
+		panic(err)
+	}
+}
+
+// RelayPanic returns an iterator over seq, and a function stopping the producer of seq, such as
+// the seq and stop returned by [Push]. Calling stopPanic(nil) is the same as calling stop.
+// Otherwise, the argument is the value recovered from a panic of the producer goroutine. stopPanic
+// calls stop, and the panic is re-raised as a [*PanicError] on the goroutine iterating the returned
+// iterator when the iteration ends, or re-raised on the producer goroutine if no goroutine is iterating it.
+// Typically, producers should “defer func() { stopPanic(recover()) }()”.
+func RelayPanic[T any](seq iter.Seq[T], stop func()) (relayed iter.Seq[T], stopPanic func(v any)) {
+	var panics relay
+	relayed = func(yield func(T) bool) {
+		panics.consumerStarted()
+		defer panics.consumerFinished()
+		seq(yield)
+	}
+	stopPanic = func(v any) {
+		if v != nil {
+			panics.producerPanicked(v)
+		}
+		stop()
+	}
+	return
+}
+
+// RelayPanic2 is the [iter.Seq2] version of [RelayPanic].
+func RelayPanic2[K, V any](seq iter.Seq2[K, V], stop func()) (relayed iter.Seq2[K, V], stopPanic func(v any)) {
+	var panics relay
+	relayed = func(yield func(K, V) bool) {
+		panics.consumerStarted()
+		defer panics.consumerFinished()
+		seq(yield)
+	}
+	stopPanic = func(v any) {
+		if v != nil {
+			panics.producerPanicked(v)
+		}
+		stop()
+	}
+	return
+}
@@ -0,0 +1,170 @@
+package iter2
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMergePanic(t *testing.T) {
+	var stopped atomic.Bool
+	seq1 := func(yield func(int) bool) {
+		defer stopped.Store(true)
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	seq2 := func(yield func(int) bool) {
+		time.Sleep(time.Millisecond * 10)
+		panic(io.ErrUnexpectedEOF)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range Merge(seq1, seq2) {
+		}
+	}()
+	err, ok := panicked.(*PanicError)
+	if !ok {
+		t.Fatal(panicked)
+	}
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(err.Stack), "TestMergePanic") {
+		t.Fatal(string(err.Stack))
+	}
+	if !stopped.Load() {
+		t.Fatal("seq1 should be stopped")
+	}
+}
+
+func TestMergePanicEarlyStop(t *testing.T) {
+	seq := func(yield func(int) bool) {
+		for i := 0; ; i++ {
+			if !yield(i) {
+				panic("cleanup")
+			}
+		}
+	}
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range Merge(seq) {
+			break
+		}
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "cleanup" {
+		t.Fatal(panicked)
+	}
+
+	panicked = nil
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range MergePriority(seq) {
+			break
+		}
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "cleanup" {
+		t.Fatal(panicked)
+	}
+}
+
+func TestRelayPanic(t *testing.T) {
+	seq, yield, stop := Push[int]()
+	seq, stopPanic := RelayPanic(seq, stop)
+	go func() {
+		defer func() { stopPanic(recover()) }()
+		yield(1) // make sure seq is being iterated
+		panic("producer")
+	}()
+
+	var s []int
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for v := range seq {
+			s = append(s, v)
+		}
+	}()
+	if !slices.Equal(s, []int{1}) {
+		t.Fatal(s)
+	}
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "producer" {
+		t.Fatal(panicked)
+	}
+}
+
+func TestRelayPanic2(t *testing.T) {
+	seq, yield, stop := PushErr[int]()
+	seq, stopPanic := RelayPanic2(seq, func() { stop(nil) })
+	go func() {
+		defer func() { stopPanic(recover()) }()
+		yield(1) // make sure seq is being iterated
+		panic("producer")
+	}()
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range seq {
+		}
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "producer" {
+		t.Fatal(panicked)
+	}
+}
+
+// recovered returns the value recovered from f.
+func recovered(f func()) (v any) {
+	defer func() {
+		v = recover()
+	}()
+	f()
+	return
+}
+
+func TestRelayPanicNoConsumer(t *testing.T) {
+	// The panic is re-raised on the producer goroutine if seq is not being iterated.
+	seq, _, stop := Push[int]()
+	_, stopPanic := RelayPanic(seq, stop)
+	if v := recovered(func() {
+		defer func() { stopPanic(recover()) }()
+		panic("producer")
+	}); v != "producer" {
+		t.Fatal(v)
+	}
+}
+
+func TestStopNoRecover(t *testing.T) {
+	// stop does not hide the panics of the goroutine deferring it.
+	_, _, stop := Push[int]()
+	_, _, stop2 := Push2[int, int]()
+	_, _, stop3, _, _ := PushBuffered[int](1, OverflowBlock)
+	_, _, stop4 := PushErr[int]()
+	for _, stop := range []func(){stop, stop2, stop3, func() { stop4(nil) }} {
+		if v := recovered(func() {
+			defer stop()
+			panic("boom")
+		}); v != "boom" {
+			t.Fatal(v)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+package iter2
+
+import (
+	"context"
+	"errors"
+	"iter"
+	"sync"
+)
+
+// goIterate iterates seq in a new goroutine, sending the values to ch, a channel with the buffer size n,
+// which is closed when the goroutine finishes. Stop stops the goroutine and waits for it to finish.
+// It is valid to call stop multiple times. After ch is closed, panicked returns the panic of seq, if any.
+func goIterate[T any](seq iter.Seq[T], n int) (ch <-chan T, stop func(), panicked func() *PanicError) {
+	doneR := make(chan struct{}) // done reading
+	values := make(chan T, n)
+	var panicErr *PanicError
+	go func() {
+		defer close(values)
+		defer func() {
+			if v := recover(); v != nil {
+				panicErr = newPanicError(v)
+			}
+		}()
+		for v := range seq {
+			select {
+			case values <- v:
+			case <-doneR:
+				return
+			}
+		}
+	}()
+	var stopOnce sync.Once
+	stop = func() {
+		stopOnce.Do(func() {
+			close(doneR)
+			for range values {
+				// wait for the goroutine to finish
+			}
+		})
+	}
+	return values, stop, func() *PanicError { return panicErr }
+}
+
+// Prefetch returns an iterator that yields the values of seq, while seq is iterated in a new goroutine
+// up to n values ahead of the consumer, so that producing values overlaps consuming them.
+// The goroutine is stopped when the iteration ends, including early stop.
+// If seq panics, the panic is re-raised on the consuming goroutine as a [*PanicError].
+// Prefetch panics if n < 0.
+func Prefetch[T any](seq iter.Seq[T], n int) iter.Seq[T] {
+	if n < 0 {
+		panic("negative count")
+	}
+	return func(yield func(T) bool) {
+		ch, stop, panicked := goIterate(seq, n)
+		defer stop()
+
+		for v := range ch {
+			if !yield(v) {
+				return
+			}
+		}
+		if err := panicked(); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// ForEachParallel calls f for each value of seq, with at most workers calls running simultaneously.
+// Seq is iterated in the calling goroutine, and ForEachParallel returns when all the calls return.
+// The first non-nil error returned by f cancels the context passed to f and stops dispatching
+// the remaining values. ForEachParallel returns all the errors returned by f, joined with
+// ctx.Err() if ctx is done, by [errors.Join].
+// If f panics, the panic is re-raised on the calling goroutine as a [*PanicError]
+// after the other calls return.
+// ForEachParallel panics if workers < 1.
+func ForEachParallel[T any](ctx context.Context, seq iter.Seq[T], workers int, f func(context.Context, T) error) error {
+	if workers < 1 {
+		panic("non-positive workers")
+	}
+	parent := ctx
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var errs []error
+	var panicErr *PanicError
+	ch := make(chan T)
+	var wg sync.WaitGroup
+	wg.Add(workers)
+	for range workers {
+		go func() {
+			defer wg.Done()
+			for v := range ch {
+				if ctx.Err() != nil {
+					continue // canceled, drain ch
+				}
+				if pe, err := call(ctx, f, v); err != nil || pe != nil {
+					mu.Lock()
+					if pe != nil && panicErr == nil {
+						panicErr = pe
+					} else if err != nil {
+						errs = append(errs, err)
+					}
+					mu.Unlock()
+					cancel()
+				}
+			}
+		}()
+	}
+
+	func() {
+		defer close(ch)
+		for v := range seq {
+			select {
+			case ch <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	wg.Wait()
+
+	if panicErr != nil {
+		panic(panicErr)
+	}
+	if err := parent.Err(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// call calls f, and recovers the panic of f as a [*PanicError].
+func call[T any](ctx context.Context, f func(context.Context, T) error, v T) (panicErr *PanicError, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			panicErr = newPanicError(p)
+		}
+	}()
+	return nil, f(ctx, v)
+}
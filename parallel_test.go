@@ -0,0 +1,130 @@
+package iter2
+
+import (
+	"context"
+	"errors"
+	"io"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestPrefetch(t *testing.T) {
+	seq := Prefetch(slices.Values([]int{1, 2, 3, 4, 5}), 2)
+	if s := slices.Collect(seq); !slices.Equal(s, []int{1, 2, 3, 4, 5}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	var stopped atomic.Bool
+	seq = Prefetch(func(yield func(int) bool) {
+		defer stopped.Store(true)
+		for i := 0; yield(i); i++ {
+		}
+	}, 3)
+	if s := slices.Collect(Take(seq, 2)); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	if !stopped.Load() {
+		t.Fatal("should be stopped")
+	}
+
+	// panic
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for range Prefetch(func(yield func(int) bool) {
+			yield(1)
+			panic("producer")
+		}, 1) {
+		}
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "producer" {
+		t.Fatal(panicked)
+	}
+}
+
+func TestForEachParallel(t *testing.T) {
+	var mu sync.Mutex
+	var s []int
+	var running, maxRunning atomic.Int32
+	err := ForEachParallel(context.Background(), slices.Values([]int{1, 2, 3, 4, 5, 6, 7, 8}), 3,
+		func(ctx context.Context, v int) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			s = append(s, v)
+			return nil
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(s)
+	if !slices.Equal(s, []int{1, 2, 3, 4, 5, 6, 7, 8}) {
+		t.Fatal(s)
+	}
+	if n := maxRunning.Load(); n > 3 {
+		t.Fatal(n)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		ForEachParallel(context.Background(), Empty[int], 0, func(context.Context, int) error { return nil })
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestForEachParallelError(t *testing.T) {
+	err := ForEachParallel(context.Background(), func(yield func(int) bool) {
+		for i := 0; yield(i); i++ {
+		}
+	}, 2, func(ctx context.Context, v int) error {
+		if v == 3 {
+			return io.ErrUnexpectedEOF
+		}
+		return nil
+	})
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = ForEachParallel(ctx, slices.Values([]int{1, 2}), 1, func(context.Context, int) error { return nil })
+	if !errors.Is(err, context.Canceled) {
+		t.Fatal(err)
+	}
+}
+
+func TestForEachParallelPanic(t *testing.T) {
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		ForEachParallel(context.Background(), slices.Values([]int{1, 2, 3}), 2, func(ctx context.Context, v int) error {
+			if v == 2 {
+				panic("worker")
+			}
+			return nil
+		})
+	}()
+	if err, ok := panicked.(*PanicError); !ok || err.Value != "worker" {
+		t.Fatal(panicked)
+	}
+}
@@ -0,0 +1,281 @@
+package iter2
+
+import (
+	"io/fs"
+	"iter"
+	"path"
+	"sync"
+	"sync/atomic"
+)
+
+// dirRead is the result of reading a directory by a dirReader.
+type dirRead struct {
+	dir       *DirEntry
+	entries   []fs.DirEntry
+	err       error
+	notify    chan<- *dirRead // if not nil, the result is also sent to notify when it is ready
+	done      chan struct{}   // closed when entries and err are ready
+	cancelled atomic.Bool     // whether the result is no longer needed
+	ahead     bool            // whether the read is counted by dirReader.ahead
+}
+
+// dirReader reads directories with a fixed number of worker goroutines fed by a queue.
+type dirReader struct {
+	fsys     fs.FS
+	mu       sync.Mutex
+	cond     sync.Cond
+	queue    []*dirRead    // reads not yet started
+	closed   bool          // whether stopped is closed
+	stopped  chan struct{} // closed when all the results are no longer needed
+	wg       sync.WaitGroup
+	ahead    int // number of reads ahead not yet consumed. Only used by the walking goroutine.
+	maxAhead int // max value of ahead
+}
+
+func newDirReader(fsys fs.FS, workers int) *dirReader {
+	r := &dirReader{fsys: fsys, stopped: make(chan struct{}), maxAhead: workers}
+	r.cond.L = &r.mu
+	r.wg.Add(workers)
+	for range workers {
+		go r.work()
+	}
+	return r
+}
+
+// work reads the directories in the queue until r is stopped.
+func (r *dirReader) work() {
+	defer r.wg.Done()
+	for {
+		r.mu.Lock()
+		for len(r.queue) == 0 && !r.closed {
+			r.cond.Wait()
+		}
+		if r.closed {
+			r.mu.Unlock()
+			return
+		}
+		result := r.queue[0]
+		r.queue[0] = nil
+		r.queue = r.queue[1:]
+		r.mu.Unlock()
+
+		if !result.cancelled.Load() {
+			if result.entries, result.err = fs.ReadDir(r.fsys, result.dir.Path); result.err != nil {
+				result.err = walkError("readdir", result.dir.Path, result.err)
+			}
+		}
+		close(result.done)
+		if result.notify != nil {
+			select {
+			case result.notify <- result:
+			case <-r.stopped:
+			}
+		}
+	}
+}
+
+// read queues the directory dir to be read. If notify is not nil,
+// the result is also sent to notify when it is ready.
+func (r *dirReader) read(dir *DirEntry, notify chan<- *dirRead) *dirRead {
+	result := &dirRead{dir: dir, notify: notify, done: make(chan struct{})}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.queue = append(r.queue, result)
+	r.cond.Signal()
+	return result
+}
+
+// readAhead is like read, but returns nil if there are already maxAhead reads ahead not yet consumed.
+// The result must be released by consume or cancel.
+func (r *dirReader) readAhead(dir *DirEntry) *dirRead {
+	if r.ahead >= r.maxAhead {
+		return nil
+	}
+	r.ahead++
+	result := r.read(dir, nil)
+	result.ahead = true
+	return result
+}
+
+// consume waits for result to be ready, and stops counting it as a read ahead.
+func (r *dirReader) consume(result *dirRead) {
+	<-result.done
+	if result.ahead {
+		result.ahead = false
+		r.ahead--
+	}
+}
+
+// cancel marks result no longer needed, and stops counting it as a read ahead.
+func (r *dirReader) cancel(result *dirRead) {
+	result.cancelled.Store(true)
+	if result.ahead {
+		result.ahead = false
+		r.ahead--
+	}
+}
+
+// stop stops all the worker goroutines and waits for them to finish.
+func (r *dirReader) stop() {
+	r.mu.Lock()
+	r.closed = true
+	close(r.stopped)
+	r.cond.Broadcast()
+	r.mu.Unlock()
+	r.wg.Wait()
+}
+
+// newChildEntry returns the DirEntry of entry in the directory dir.
+func newChildEntry(dir *DirEntry, entry fs.DirEntry) *DirEntry {
+	return &DirEntry{
+		Path:    path.Join(dir.Path, entry.Name()),
+		RelPath: path.Join(dir.RelPath, entry.Name()),
+		Entry:   entry,
+		Depth:   dir.Depth + 1,
+	}
+}
+
+// ParallelWalkDir is like [WalkDir], but reads directories in a pool of workers goroutines.
+// If ordered is true, the entries are yielded in the same order as WalkDir, and at most workers
+// subdirectories not yet walked are read ahead while the entries before them are being yielded. Otherwise, the entries of a
+// directory are yielded as soon as the directory is read, and the order is unspecified, except that
+// a directory is always yielded before its entries.
+// The SkipDir and SkipAll methods of the yielded DirEntry work the same way as WalkDir, and the entries
+// skipped are never yielded, even if they have already been read. SkipDir on a file skips the
+// remaining entries of its directory not yet yielded.
+// The iteration is done in the calling goroutine, and all the reading goroutines are finished
+// when the iteration ends, including early stop.
+// ParallelWalkDir panics if workers < 1.
+func ParallelWalkDir(fsys fs.FS, root string, workers int, ordered bool) iter.Seq2[*DirEntry, error] {
+	if workers < 1 {
+		panic("non-positive workers")
+	}
+	return func(yield func(*DirEntry, error) bool) {
+		info, err := fs.Stat(fsys, root)
+		if err != nil {
+			yield(&DirEntry{Path: root, RelPath: "."}, walkError("stat", root, err))
+			return
+		}
+		dir := &DirEntry{Path: root, RelPath: ".", Entry: fs.FileInfoToDirEntry(info)}
+		if !yield(dir, nil) || dir.err != nil || !info.IsDir() {
+			return
+		}
+		r := newDirReader(fsys, workers)
+		defer r.stop()
+		if ordered {
+			walkOrdered(r, r.read(dir, nil), yield)
+		} else {
+			walkUnordered(r, dir, yield)
+		}
+	}
+}
+
+// walkOrdered yields the entries of the directory being read by result in depth-first lexical order.
+// It returns false if the walk should stop.
+func walkOrdered(r *dirReader, result *dirRead, yield func(*DirEntry, error) bool) bool {
+	r.consume(result)
+	dir := result.dir
+	if result.err != nil {
+		if !yield(dir, result.err) || dir.err == fs.SkipAll {
+			return false
+		} else if dir.err == fs.SkipDir {
+			return true
+		}
+	}
+	entries := result.entries
+	// Read the subdirectories ahead, as many as r allows.
+	reads := make([]*dirRead, len(entries))
+	next := 0 // index of the next entry to be read ahead
+	readAhead := func(from int) {
+		for next = max(next, from); next < len(entries); next++ {
+			if !entries[next].IsDir() {
+				continue
+			}
+			if reads[next] = r.readAhead(newChildEntry(dir, entries[next])); reads[next] == nil {
+				return
+			}
+		}
+	}
+	defer func() {
+		for _, read := range reads {
+			if read != nil {
+				r.cancel(read)
+			}
+		}
+	}()
+	for i, entry := range entries {
+		readAhead(i)
+		var child *DirEntry
+		if reads[i] != nil {
+			child = reads[i].dir
+		} else {
+			child = newChildEntry(dir, entry)
+		}
+		if !yield(child, nil) {
+			return false
+		}
+		switch child.err {
+		case fs.SkipAll:
+			return false
+		case fs.SkipDir:
+			if !entry.IsDir() {
+				return true // skip the remaining entries
+			}
+			if reads[i] != nil {
+				r.cancel(reads[i])
+				reads[i] = nil
+			}
+			continue
+		}
+		if entry.IsDir() {
+			read := reads[i]
+			if read == nil {
+				read = r.read(child, nil)
+			}
+			reads[i] = nil
+			if !walkOrdered(r, read, yield) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// walkUnordered yields the entries of the tree rooted at root as soon as the directories are read.
+func walkUnordered(r *dirReader, root *DirEntry, yield func(*DirEntry, error) bool) {
+	results := make(chan *dirRead)
+	pending := 1
+	r.read(root, results)
+	for pending > 0 {
+		result := <-results
+		pending--
+		dir := result.dir
+		if result.err != nil {
+			if !yield(dir, result.err) || dir.err == fs.SkipAll {
+				return
+			} else if dir.err == fs.SkipDir {
+				continue
+			}
+		}
+	entries:
+		for _, entry := range result.entries {
+			child := newChildEntry(dir, entry)
+			if !yield(child, nil) {
+				return
+			}
+			switch child.err {
+			case fs.SkipAll:
+				return
+			case fs.SkipDir:
+				if !entry.IsDir() {
+					break entries // skip the remaining entries
+				}
+				continue
+			}
+			if entry.IsDir() {
+				pending++
+				r.read(child, results)
+			}
+		}
+	}
+}
@@ -0,0 +1,161 @@
+package iter2
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"runtime"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"testing/fstest"
+)
+
+func TestParallelWalkDir(t *testing.T) {
+	want := walkPaths(t, WalkDir(walkFS, "."))
+	for _, workers := range []int{1, 4} {
+		if s := walkPaths(t, ParallelWalkDir(walkFS, ".", workers, true)); !slices.Equal(s, want) {
+			t.Fatal(s)
+		}
+
+		s := walkPaths(t, ParallelWalkDir(walkFS, ".", workers, false))
+		for i, p := range s {
+			if p == "." {
+				continue
+			}
+			if parent := path.Dir(p); !slices.Contains(s[:i], parent) {
+				t.Fatal(p, "yielded before", parent)
+			}
+		}
+		slices.Sort(s)
+		if !slices.Equal(s, slices.Sorted(slices.Values(want))) {
+			t.Fatal(s)
+		}
+	}
+
+	// early stop
+	if s := walkPaths(t, Take2(ParallelWalkDir(walkFS, ".", 2, true), 3)); !slices.Equal(s, want[:3]) {
+		t.Fatal(s)
+	}
+	if s := walkPaths(t, Take2(ParallelWalkDir(walkFS, ".", 2, false), 3)); len(s) != 3 {
+		t.Fatal(s)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		ParallelWalkDir(walkFS, ".", 0, true)
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestParallelWalkDirSkip(t *testing.T) {
+	for _, ordered := range []bool{true, false} {
+		var s []string
+		for d, err := range ParallelWalkDir(walkFS, ".", 3, ordered) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			s = append(s, d.Path)
+			if d.Path == "dir1" || d.Path == "vendor/lib/f.go" {
+				d.SkipDir()
+			}
+		}
+		slices.Sort(s)
+		if !slices.Equal(s, []string{".", ".hidden", ".hidden/x.txt", "a.txt", "dir1", "dir3", "dir3/dir4", "dir3/dir4/dir5",
+			"dir3/dir4/dir5/h", "dir3/dir4/dir5/i", "vendor", "vendor/lib", "vendor/lib/f.go"}) {
+			t.Fatal(s)
+		}
+
+		s = nil
+		for d, err := range ParallelWalkDir(walkFS, ".", 3, ordered) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			s = append(s, d.Path)
+			if strings.HasPrefix(d.Path, "dir3/") {
+				d.SkipAll()
+			}
+		}
+		if slices.ContainsFunc(s, func(p string) bool { return strings.HasPrefix(p, "dir3/dir4/") }) {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestParallelWalkDirRelPath(t *testing.T) {
+	for d, err := range ParallelWalkDir(walkFS, "dir1", 2, false) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Path != path.Join("dir1", d.RelPath) || d.Depth != pathDepth(d.RelPath) {
+			t.Fatal(d.Path, d.RelPath, d.Depth)
+		}
+	}
+}
+
+func TestParallelWalkDirErr(t *testing.T) {
+	var errs []error
+	for _, err := range ParallelWalkDir(os.DirFS("testdata"), "NO THIS FILE", 2, true) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrNotExist) {
+		t.Fatal(errs)
+	}
+}
+
+// readDirCounter counts the calls to ReadDir.
+type readDirCounter struct {
+	fs.FS
+	calls atomic.Int64
+}
+
+func (fsys *readDirCounter) ReadDir(name string) ([]fs.DirEntry, error) {
+	fsys.calls.Add(1)
+	return fs.ReadDir(fsys.FS, name)
+}
+
+// wideFS returns a tree with n directories in the root, each containing a file.
+func wideFS(n int) fstest.MapFS {
+	fsys := fstest.MapFS{}
+	for i := range n {
+		fsys[fmt.Sprintf("d%04d/f", i)] = &fstest.MapFile{}
+	}
+	return fsys
+}
+
+func TestParallelWalkDirBounded(t *testing.T) {
+	const workers = 4
+	fsys := &readDirCounter{FS: wideFS(500)}
+	goroutines := runtime.NumGoroutine()
+	for _, ordered := range []bool{true, false} {
+		fsys.calls.Store(0)
+		var dirs int64
+		for d, err := range ParallelWalkDir(fsys, ".", workers, ordered) {
+			if err != nil {
+				t.Fatal(err)
+			}
+			if n := runtime.NumGoroutine(); n > goroutines+workers {
+				t.Fatal("too many goroutines", n)
+			}
+			if !d.Entry.IsDir() {
+				continue
+			}
+			dirs++
+			// At most workers directories not yet yielded are read ahead.
+			if ordered && fsys.calls.Load() > dirs+workers {
+				t.Fatal("too many reads ahead", fsys.calls.Load(), dirs)
+			}
+		}
+		if calls := fsys.calls.Load(); calls != 501 {
+			t.Fatal(calls)
+		}
+	}
+}
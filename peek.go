@@ -0,0 +1,79 @@
+package iter2
+
+import (
+	"iter"
+	"slices"
+)
+
+// Peekable is a pull iterator with lookahead and pushback.
+// Peekable is not safe for concurrent use.
+type Peekable[T any] struct {
+	next func() (T, bool)
+	stop func()
+	buf  []T // values to be returned before pulling next. buf[0] is the first.
+}
+
+// NewPeekable returns a Peekable that pulls values from seq.
+// Callers should call Stop when they are done with the Peekable.
+// Typically, callers should “defer p.Stop()”.
+func NewPeekable[T any](seq iter.Seq[T]) *Peekable[T] {
+	next, stop := iter.Pull(seq)
+	return &Peekable[T]{next: next, stop: stop}
+}
+
+// Next returns the next value and true, or the zero value and false if there is no more value.
+func (p *Peekable[T]) Next() (v T, ok bool) {
+	if len(p.buf) > 0 {
+		v = p.buf[0]
+		var zero T
+		p.buf[0] = zero
+		p.buf = p.buf[1:]
+		return v, true
+	}
+	return p.next()
+}
+
+// Peek returns the value that would be returned by the (k+1)th call to Next without consuming it.
+// That is, Peek(0) returns the value Next would return. Peek returns the zero value and false
+// if there are less than k+1 values left.
+// Peek panics if k < 0.
+func (p *Peekable[T]) Peek(k int) (v T, ok bool) {
+	if k < 0 {
+		panic("negative index")
+	}
+	for len(p.buf) <= k {
+		v, ok := p.next()
+		if !ok {
+			return v, false
+		}
+		p.buf = append(p.buf, v)
+	}
+	return p.buf[k], true
+}
+
+// PushBack pushes v back, so v is the next value returned by Next.
+// Values pushed back are returned in the reverse order.
+func (p *Peekable[T]) PushBack(v T) {
+	p.buf = slices.Insert(p.buf, 0, v)
+}
+
+// All returns an iterator over the remaining values, including the values peeked and pushed back.
+// Values yielded are consumed from p.
+func (p *Peekable[T]) All() iter.Seq[T] {
+	return func(yield func(T) bool) {
+		for {
+			v, ok := p.Next()
+			if !ok || !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Stop ends the iteration. Next and Peek return no value after Stop is called,
+// except the values pushed back after that.
+// It is valid to call Stop multiple times.
+func (p *Peekable[T]) Stop() {
+	p.stop()
+	p.buf = nil
+}
@@ -0,0 +1,26 @@
+package iter2_test
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/mkch/iter2"
+)
+
+func ExamplePeekable() {
+	// Group consecutive equal values.
+	p := iter2.NewPeekable(slices.Values([]int{1, 1, 2, 3, 3, 3}))
+	defer p.Stop()
+	for v, ok := p.Next(); ok; v, ok = p.Next() {
+		group := []int{v}
+		for next, ok := p.Peek(0); ok && next == v; next, ok = p.Peek(0) {
+			p.Next()
+			group = append(group, next)
+		}
+		fmt.Println(group)
+	}
+	// Output:
+	// [1 1]
+	// [2]
+	// [3 3 3]
+}
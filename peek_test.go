@@ -0,0 +1,66 @@
+package iter2
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestPeekable(t *testing.T) {
+	p := NewPeekable(slices.Values([]int{1, 2, 3, 4}))
+	defer p.Stop()
+
+	if v, ok := p.Peek(0); !ok || v != 1 {
+		t.Fatal(v, ok)
+	}
+	if v, ok := p.Peek(2); !ok || v != 3 {
+		t.Fatal(v, ok)
+	}
+	if v, ok := p.Peek(4); ok {
+		t.Fatal(v, ok)
+	}
+	if v, ok := p.Next(); !ok || v != 1 {
+		t.Fatal(v, ok)
+	}
+	p.PushBack(10)
+	p.PushBack(20)
+	if v, ok := p.Peek(0); !ok || v != 20 {
+		t.Fatal(v, ok)
+	}
+	if v, ok := p.Next(); !ok || v != 20 {
+		t.Fatal(v, ok)
+	}
+	if s := slices.Collect(Take(p.All(), 2)); !slices.Equal(s, []int{10, 2}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(p.All()); !slices.Equal(s, []int{3, 4}) {
+		t.Fatal(s)
+	}
+	if v, ok := p.Next(); ok {
+		t.Fatal(v, ok)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		p.Peek(-1)
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestPeekableStop(t *testing.T) {
+	p := NewPeekable(slices.Values([]int{1, 2, 3}))
+	p.Peek(1)
+	p.Stop()
+	if v, ok := p.Next(); ok {
+		t.Fatal(v, ok)
+	}
+	p.PushBack(1)
+	if s := slices.Collect(p.All()); !slices.Equal(s, []int{1}) {
+		t.Fatal(s)
+	}
+	p.Stop()
+}
@@ -0,0 +1,200 @@
+package iter2
+
+import (
+	"iter"
+	"sync"
+	"sync/atomic"
+)
+
+// OverflowPolicy decides what [PushBuffered] does when its buffer is full.
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota // Blocks yield until there is room in the buffer.
+	OverflowDropNewest                       // Drops the value being pushed.
+	OverflowDropOldest                       // Drops the oldest value in the buffer to make room.
+	OverflowError                            // Fails with ErrBufferFull. See [PushBuffered].
+)
+
+// PushBuffered is like [Push], but the values pushed are kept in a buffer of the capacity,
+// so yield does not wait for the consumer until the buffer is full.
+// What yield does when the buffer is full is decided by policy. The values dropped by
+// [OverflowDropNewest] and [OverflowDropOldest] are not yielded, and are counted by dropped.
+// With [OverflowError], yield returns false instead of pushing the value, and the iteration is stopped
+// as if stop is called. From then on, yield always returns false, and err returns [ErrBufferFull].
+// Otherwise err returns nil.
+// The values remaining in the buffer are still yielded after stop is called.
+// PushBuffered panics if capacity < 1.
+func PushBuffered[T any](capacity int, policy OverflowPolicy) (seq iter.Seq[T], yield func(T) bool, stop func(), dropped func() int64, err func() error) {
+	if capacity < 1 {
+		panic("non-positive capacity")
+	}
+	var ch = make(chan T, capacity)
+	var doneW = make(chan struct{})
+	var doneR = make(chan struct{})
+	var droppedCount atomic.Int64
+	var overflowed atomic.Bool // whether the buffer overflowed with OverflowError
+	seq = func(yield func(T) bool) {
+		defer close(doneR)
+		for {
+			select {
+			case v := <-ch:
+				if !yield(v) {
+					return
+				}
+			case <-doneW:
+				// drain the buffer
+				for {
+					select {
+					case v := <-ch:
+						if !yield(v) {
+							return
+						}
+					default:
+						return
+					}
+				}
+			}
+		}
+	}
+	var dropLock sync.Mutex // serializes DropOldest yields
+	yield = func(v T) bool {
+		select {
+		case <-doneR:
+			return false
+		default:
+		}
+		if overflowed.Load() {
+			return false
+		}
+		if policy == OverflowBlock {
+			select {
+			case ch <- v:
+				return true
+			case <-doneR:
+				return false
+			}
+		}
+		if policy == OverflowDropOldest {
+			dropLock.Lock()
+			defer dropLock.Unlock()
+		}
+		for {
+			select {
+			case ch <- v:
+				return true
+			default:
+			}
+			switch policy {
+			case OverflowDropNewest:
+				droppedCount.Add(1)
+				return true
+			case OverflowDropOldest:
+				select {
+				case <-ch:
+					droppedCount.Add(1)
+				default: // consumed by seq
+				}
+			default:
+				overflowed.Store(true)
+				stop()
+				return false
+			}
+		}
+	}
+	var stopLock sync.Mutex
+	stop = func() {
+		stopLock.Lock()
+		defer stopLock.Unlock()
+
+		select {
+		case <-doneW:
+			return
+		default:
+			close(doneW)
+		}
+	}
+	dropped = droppedCount.Load
+	err = func() error {
+		if overflowed.Load() {
+			return ErrBufferFull
+		}
+		return nil
+	}
+	return
+}
+
+// PushErr is like [Push], but stop takes an error. If stop is called with a non-nil error,
+// the error is yielded with the zero value of T as the final element of seq, the way
+// [io.PipeWriter.CloseWithError] reports an error to the reader.
+// Only the first call to stop takes effect.
+func PushErr[T any]() (seq iter.Seq2[T, error], yield func(T) bool, stop func(err error)) {
+	var ch = make(chan T)
+	var doneW = make(chan struct{})
+	var doneR = make(chan struct{})
+	var stopErr error // set before doneW is closed
+	seq = func(yield func(T, error) bool) {
+		defer close(doneR)
+		for {
+			select {
+			case v := <-ch:
+				if !yield(v, nil) {
+					return
+				}
+			case <-doneW:
+				if stopErr != nil {
+					var zero T
+					yield(zero, stopErr)
+				}
+				return
+			}
+		}
+	}
+	yield = func(v T) bool {
+		select {
+		case ch <- v:
+			return true
+		case <-doneR:
+			return false
+		}
+	}
+	var stopLock sync.Mutex
+	stop = func(err error) {
+		stopLock.Lock()
+		defer stopLock.Unlock()
+
+		select {
+		case <-doneW:
+			return
+		default:
+			stopErr = err
+			close(doneW)
+		}
+	}
+	return
+}
+
+// FromCallback returns an iterator over the values emitted by f, for adapting synchronous
+// callback-style APIs without goroutines. Each call to emit yields the value with a nil error,
+// and returns false if the iteration stopped early, in which case f should return as soon as possible.
+// If f returns a non-nil error, the error is yielded with the zero value of T as the final element,
+// unless the iteration has stopped early.
+// Unlike [Push], emit must be called synchronously from f, before f returns.
+func FromCallback[T any](f func(emit func(T) bool) error) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		stopped := false
+		err := f(func(v T) bool {
+			if stopped {
+				return false
+			}
+			if !yield(v, nil) {
+				stopped = true
+			}
+			return !stopped
+		})
+		if err != nil && !stopped {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
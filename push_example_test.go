@@ -0,0 +1,38 @@
+package iter2_test
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+
+	"github.com/mkch/iter2"
+)
+
+func ExampleFromCallback() {
+	f, err := parser.ParseFile(token.NewFileSet(), "", "package p; var a, b = c, d", 0)
+	if err != nil {
+		panic(err)
+	}
+	idents := iter2.FromCallback(func(emit func(string) bool) error {
+		ast.Inspect(f, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok {
+				return emit(ident.Name)
+			}
+			return true
+		})
+		return nil
+	})
+	for name, err := range idents {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(name)
+	}
+	// Output:
+	// p
+	// a
+	// b
+	// c
+	// d
+}
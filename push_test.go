@@ -0,0 +1,189 @@
+package iter2
+
+import (
+	"io"
+	"maps"
+	"slices"
+	"testing"
+)
+
+func TestPushBuffered(t *testing.T) {
+	seq, yield, stop, dropped, _ := PushBuffered[int](3, OverflowBlock)
+	go func() {
+		defer stop()
+		for i := range 10 {
+			if !yield(i) {
+				return
+			}
+		}
+	}()
+	if s := slices.Collect(seq); !slices.Equal(s, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+		t.Fatal(s)
+	}
+	if n := dropped(); n != 0 {
+		t.Fatal(n)
+	}
+
+	// early stop
+	seq, yield, stop, _, _ = PushBuffered[int](3, OverflowBlock)
+	go func() {
+		defer stop()
+		for i := 0; ; i++ {
+			if !yield(i) {
+				return
+			}
+		}
+	}()
+	if s := slices.Collect(Take(seq, 5)); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		PushBuffered[int](0, OverflowBlock)
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestPushBufferedDropNewest(t *testing.T) {
+	seq, yield, stop, dropped, _ := PushBuffered[int](2, OverflowDropNewest)
+	for i := range 5 {
+		if !yield(i) {
+			t.Fatal(i)
+		}
+	}
+	stop()
+	if s := slices.Collect(seq); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	if n := dropped(); n != 3 {
+		t.Fatal(n)
+	}
+	if yield(5) {
+		t.Fatal("should not yield after seq ends")
+	}
+}
+
+func TestPushBufferedDropOldest(t *testing.T) {
+	seq, yield, stop, dropped, _ := PushBuffered[int](2, OverflowDropOldest)
+	for i := range 5 {
+		if !yield(i) {
+			t.Fatal(i)
+		}
+	}
+	stop()
+	if s := slices.Collect(seq); !slices.Equal(s, []int{3, 4}) {
+		t.Fatal(s)
+	}
+	if n := dropped(); n != 3 {
+		t.Fatal(n)
+	}
+}
+
+func TestPushBufferedError(t *testing.T) {
+	seq, yield, stop, _, err := PushBuffered[int](2, OverflowError)
+	defer stop()
+	if !yield(0) || !yield(1) || err() != nil {
+		t.Fatal("should not overflow")
+	}
+	if yield(2) {
+		t.Fatal("should overflow")
+	}
+	if yield(3) || err() != ErrBufferFull {
+		t.Fatal(err())
+	}
+	// The iteration is stopped after the buffered values.
+	if s := slices.Collect(seq); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+}
+
+func TestPushErr(t *testing.T) {
+	seq, yield, stop := PushErr[int]()
+	go func() {
+		yield(1)
+		yield(2)
+		stop(io.ErrUnexpectedEOF)
+		stop(nil)
+	}()
+	var s []int
+	var errs []error
+	for v, err := range seq {
+		s = append(s, v)
+		errs = append(errs, err)
+	}
+	if !slices.Equal(s, []int{1, 2, 0}) {
+		t.Fatal(s)
+	}
+	if !slices.Equal(errs, []error{nil, nil, io.ErrUnexpectedEOF}) {
+		t.Fatal(errs)
+	}
+
+}
+
+func TestPushErrNil(t *testing.T) {
+	seq, yield, stop := PushErr[int]()
+	go func() {
+		defer stop(nil)
+		yield(1)
+	}()
+	if m := maps.Collect(seq); !maps.Equal(m, map[int]error{1: nil}) {
+		t.Fatal(m)
+	}
+
+	// early stop
+	seq2, yield2, stop2 := PushErr[int]()
+	go func() {
+		defer stop2(io.EOF)
+		for i := 0; yield2(i); i++ {
+		}
+	}()
+	if m := maps.Collect(Take2(seq2, 2)); !maps.Equal(m, map[int]error{0: nil, 1: nil}) {
+		t.Fatal(m)
+	}
+}
+
+func TestFromCallback(t *testing.T) {
+	seq := FromCallback(func(emit func(int) bool) error {
+		for i := range 3 {
+			if !emit(i) {
+				return io.ErrClosedPipe
+			}
+		}
+		return io.EOF
+	})
+	var s []int
+	var errs []error
+	for v, err := range seq {
+		s = append(s, v)
+		errs = append(errs, err)
+	}
+	if !slices.Equal(s, []int{0, 1, 2, 0}) {
+		t.Fatal(s)
+	}
+	if !slices.Equal(errs, []error{nil, nil, nil, io.EOF}) {
+		t.Fatal(errs)
+	}
+
+	// early stop
+	if m := maps.Collect(Take2(seq, 2)); !maps.Equal(m, map[int]error{0: nil, 1: nil}) {
+		t.Fatal(m)
+	}
+
+	// emit after early stop
+	seq = FromCallback(func(emit func(int) bool) error {
+		emit(1)
+		if emit(2) {
+			t.Fatal("should return false")
+		}
+		return nil
+	})
+	if m := maps.Collect(Take2(seq, 1)); !maps.Equal(m, map[int]error{1: nil}) {
+		t.Fatal(m)
+	}
+}
@@ -0,0 +1,174 @@
+package iter2
+
+import (
+	"errors"
+	"iter"
+	"sync"
+)
+
+// ErrBufferFull is the panic value or error used when a bounded buffer can not hold more values.
+var ErrBufferFull = errors.New("buffer full")
+
+// tee is the state shared by the iterators returned by [Tee].
+type tee[T any] struct {
+	seq   iter.Seq[T]
+	size  int  // max len(buf), unbounded if <= 0
+	block bool // whether to block or panic if buf is full
+
+	mu      sync.Mutex
+	cond    sync.Cond
+	next    func() (T, bool)
+	stop    func()
+	buf     []T   // values not yet consumed by all iterators. buf[0] is the value at index base.
+	base    int   // index of buf[0]
+	pos     []int // index of the next value of each iterator, -1 if the iterator is done
+	pulling bool  // whether next is being called
+	done    bool  // whether seq is exhausted
+}
+
+// get returns the next value of the ith iterator.
+func (t *tee[T]) get(i int) (v T, ok bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for {
+		if t.pos[i] < 0 {
+			return
+		}
+		if idx := t.pos[i] - t.base; idx < len(t.buf) {
+			v = t.buf[idx]
+			t.pos[i]++
+			t.trim()
+			return v, true
+		}
+		if t.done {
+			return
+		}
+		if t.pulling {
+			t.cond.Wait()
+			continue
+		}
+		if t.size > 0 && len(t.buf) >= t.size {
+			if !t.block {
+				panic(ErrBufferFull)
+			}
+			t.cond.Wait()
+			continue
+		}
+		t.pull()
+	}
+}
+
+// pull pulls the next value of seq into buf.
+// t.mu must be held. It is released while pulling.
+func (t *tee[T]) pull() {
+	if t.next == nil {
+		t.next, t.stop = iter.Pull(t.seq)
+	}
+	t.pulling = true
+	t.mu.Unlock()
+	var v T
+	var ok bool
+	defer func() {
+		t.mu.Lock()
+		t.pulling = false
+		if ok {
+			t.buf = append(t.buf, v)
+		} else {
+			t.done = true // seq is exhausted or panicked
+		}
+		t.cond.Broadcast()
+	}()
+	v, ok = t.next()
+}
+
+// trim drops the values consumed by all iterators from buf.
+// t.mu must be held.
+func (t *tee[T]) trim() {
+	low := -1
+	for _, pos := range t.pos {
+		if pos >= 0 && (low < 0 || pos < low) {
+			low = pos
+		}
+	}
+	if low < 0 {
+		// All iterators are done.
+		t.buf = nil
+		if t.stop != nil {
+			t.stop()
+		}
+		return
+	}
+	if n := low - t.base; n > 0 {
+		clear(t.buf[:n])
+		t.buf = t.buf[n:]
+		t.base = low
+		t.cond.Broadcast()
+	}
+}
+
+// detach marks the ith iterator done.
+func (t *tee[T]) detach(i int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.pos[i] < 0 {
+		return
+	}
+	t.pos[i] = -1
+	t.trim()
+}
+
+func newTee[T any](seq iter.Seq[T], n, size int, block bool) []iter.Seq[T] {
+	if n < 0 {
+		panic("negative count")
+	}
+	t := &tee[T]{seq: seq, size: size, block: block, pos: make([]int, n)}
+	t.cond.L = &t.mu
+	seqs := make([]iter.Seq[T], n)
+	for i := range seqs {
+		seqs[i] = func(yield func(T) bool) {
+			defer t.detach(i)
+			for {
+				v, ok := t.get(i)
+				if !ok || !yield(v) {
+					return
+				}
+			}
+		}
+	}
+	return seqs
+}
+
+// Tee returns n iterators that each yield the same values as seq, while seq is iterated only once.
+// It is safe to iterate the returned iterators from multiple goroutines simultaneously.
+// The values not yet yielded by all the returned iterators are kept in a buffer.
+// If size > 0, an iterator that is size values ahead of the slowest one blocks until the others catch up,
+// otherwise the buffer is unbounded. Each returned iterator can be iterated only once and stops
+// holding the buffer when its iteration ends, so every returned iterator should be iterated eventually.
+// Tee panics if n < 0.
+func Tee[T any](seq iter.Seq[T], n, size int) []iter.Seq[T] {
+	return newTee(seq, n, size, true)
+}
+
+// TeeNoBlock is like [Tee], but an iterator that is size values ahead of the slowest one panics
+// with [ErrBufferFull] instead of blocking. TeeNoBlock is useful when the returned iterators are
+// iterated in the same goroutine, where blocking would be a deadlock.
+func TeeNoBlock[T any](seq iter.Seq[T], n, size int) []iter.Seq[T] {
+	return newTee(seq, n, size, false)
+}
+
+// Unzip returns an iterator over keys and an iterator over values in seq2, while seq2 is iterated only once.
+// The pairs not yet yielded by both the returned iterators are kept in an unbounded buffer. See [Tee].
+func Unzip[K, V any](seq2 iter.Seq2[K, V]) (iter.Seq[K], iter.Seq[V]) {
+	type pair struct {
+		K K
+		V V
+	}
+	seqs := Tee(func(yield func(pair) bool) {
+		for k, v := range seq2 {
+			if !yield(pair{k, v}) {
+				return
+			}
+		}
+	}, 2, 0)
+	return Map(seqs[0], func(p pair) K { return p.K }), Map(seqs[1], func(p pair) V { return p.V })
+}
@@ -0,0 +1,19 @@
+package iter2_test
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/mkch/iter2"
+)
+
+func ExampleUnzip() {
+	m := map[string]int{"one": 1, "two": 2, "three": 3}
+	keys, values := iter2.Unzip(maps.All(m))
+	fmt.Println(slices.Sorted(keys))
+	fmt.Println(slices.Sorted(values))
+	// Output:
+	// [one three two]
+	// [1 2 3]
+}
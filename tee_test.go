@@ -0,0 +1,104 @@
+package iter2
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestTee(t *testing.T) {
+	var pulled int
+	seq := func(yield func(int) bool) {
+		for i := range 5 {
+			pulled++
+			if !yield(i) {
+				return
+			}
+		}
+	}
+	seqs := Tee(seq, 3, 0)
+	if s := slices.Collect(seqs[0]); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(Take(seqs[1], 2)); !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(seqs[2]); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+	if pulled != 5 {
+		t.Fatal(pulled)
+	}
+	// iterated only once
+	if s := slices.Collect(seqs[0]); len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	if seqs := Tee(seq, 0, 0); len(seqs) != 0 {
+		t.Fatal(seqs)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		Tee(seq, -1, 0)
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestTeeBlock(t *testing.T) {
+	seqs := Tee(slices.Values([]int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}), 3, 2)
+	results := make([][]int, len(seqs))
+	var wg sync.WaitGroup
+	for i, seq := range seqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = slices.Collect(seq)
+		}()
+	}
+	wg.Wait()
+	for _, s := range results {
+		if !slices.Equal(s, []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}) {
+			t.Fatal(s)
+		}
+	}
+}
+
+func TestTeeNoBlock(t *testing.T) {
+	seqs := TeeNoBlock(slices.Values([]int{0, 1, 2, 3, 4}), 2, 2)
+	var s []int
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		for v := range seqs[0] {
+			s = append(s, v)
+		}
+	}()
+	if panicked != ErrBufferFull {
+		t.Fatal(panicked)
+	}
+	if !slices.Equal(s, []int{0, 1}) {
+		t.Fatal(s)
+	}
+	// seqs[0] is done, seqs[1] is not limited by it any more.
+	if s := slices.Collect(seqs[1]); !slices.Equal(s, []int{0, 1, 2, 3, 4}) {
+		t.Fatal(s)
+	}
+}
+
+func TestUnzip(t *testing.T) {
+	keys, values := Unzip(slices.All([]string{"a", "b", "c"}))
+	if s := slices.Collect(keys); !slices.Equal(s, []int{0, 1, 2}) {
+		t.Fatal(s)
+	}
+	if s := slices.Collect(values); !slices.Equal(s, []string{"a", "b", "c"}) {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,164 @@
+package iter2
+
+import (
+	"context"
+	"iter"
+	"math"
+	"time"
+)
+
+// Clock tells the time for the time-based iterators, such as [Throttle].
+// It can be replaced to test them without wall-clock sleeps.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel.
+	After(d time.Duration) <-chan time.Time
+}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// SystemClock is the Clock using the system time.
+var SystemClock Clock = systemClock{}
+
+// Throttle returns an iterator that yields the values of seq at most rate values per second,
+// with bursts of at most burst values, by a token bucket. Seq is not iterated further while
+// waiting for a token. The iteration stops when ctx is done.
+// If clock is nil, SystemClock is used.
+// Throttle panics if rate <= 0 or burst < 1.
+func Throttle[T any](ctx context.Context, seq iter.Seq[T], rate float64, burst int, clock Clock) iter.Seq[T] {
+	if rate <= 0 {
+		panic("non-positive rate")
+	}
+	if burst < 1 {
+		panic("non-positive burst")
+	}
+	if clock == nil {
+		clock = SystemClock
+	}
+	return func(yield func(T) bool) {
+		tokens := float64(burst)
+		last := clock.Now()
+		for v := range seq {
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				now := clock.Now()
+				tokens = min(float64(burst), tokens+now.Sub(last).Seconds()*rate)
+				last = now
+				if tokens >= 1 {
+					break
+				}
+				wait := time.Duration(math.Ceil((1 - tokens) / rate * float64(time.Second)))
+				select {
+				case <-clock.After(wait):
+				case <-ctx.Done():
+					return
+				}
+			}
+			tokens--
+			if !yield(v) {
+				return
+			}
+		}
+	}
+}
+
+// Debounce returns an iterator that yields a value of seq only after seq has yielded no newer value
+// for the quiet duration. The last value of seq is yielded when seq ends, without waiting.
+// Seq is iterated in a new goroutine, which is stopped when the iteration ends, including early stop.
+// The iteration stops when ctx is done. If seq panics, the panic is re-raised on the consuming
+// goroutine as a [*PanicError]. If clock is nil, SystemClock is used.
+func Debounce[T any](ctx context.Context, seq iter.Seq[T], quiet time.Duration, clock Clock) iter.Seq[T] {
+	if clock == nil {
+		clock = SystemClock
+	}
+	return func(yield func(T) bool) {
+		ch, stop, panicked := goIterate(seq, 0)
+		defer stop()
+		var pending T
+		var hasPending bool
+		var timer <-chan time.Time
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					if err := panicked(); err != nil {
+						panic(err)
+					}
+					if hasPending {
+						yield(pending)
+					}
+					return
+				}
+				pending, hasPending = v, true
+				timer = clock.After(quiet)
+			case <-timer:
+				timer = nil
+				hasPending = false
+				if !yield(pending) {
+					return
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// Sample returns an iterator that yields the latest value of seq once every interval,
+// if seq has yielded any value since the last one sampled. The latest value not yet sampled
+// is yielded when seq ends, without waiting.
+// Seq is iterated in a new goroutine, which is stopped when the iteration ends, including early stop.
+// The iteration stops when ctx is done. If seq panics, the panic is re-raised on the consuming
+// goroutine as a [*PanicError]. If clock is nil, SystemClock is used.
+// Sample panics if interval <= 0.
+func Sample[T any](ctx context.Context, seq iter.Seq[T], interval time.Duration, clock Clock) iter.Seq[T] {
+	if interval <= 0 {
+		panic("non-positive interval")
+	}
+	if clock == nil {
+		clock = SystemClock
+	}
+	return func(yield func(T) bool) {
+		ch, stop, panicked := goIterate(seq, 0)
+		defer stop()
+		var latest T
+		var hasLatest bool
+		tick := clock.After(interval)
+		for {
+			select {
+			case v, ok := <-ch:
+				if !ok {
+					if err := panicked(); err != nil {
+						panic(err)
+					}
+					if hasLatest {
+						yield(latest)
+					}
+					return
+				}
+				latest, hasLatest = v, true
+			case <-tick:
+				tick = clock.After(interval)
+				if hasLatest {
+					hasLatest = false
+					if !yield(latest) {
+						return
+					}
+				}
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
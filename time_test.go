@@ -0,0 +1,165 @@
+package iter2
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock is a Clock whose time only changes by Advance.
+// If auto is true, After advances the time by the duration and returns immediately.
+type fakeClock struct {
+	auto bool
+
+	mu     sync.Mutex
+	cond   sync.Cond
+	now    time.Time
+	timers []fakeTimer
+	afters int // number of After calls
+}
+
+type fakeTimer struct {
+	at time.Time
+	ch chan time.Time
+}
+
+func newFakeClock(auto bool) *fakeClock {
+	c := &fakeClock{auto: auto, now: time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)}
+	c.cond.L = &c.mu
+	return c
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.afters++
+	c.cond.Broadcast()
+	ch := make(chan time.Time, 1)
+	at := c.now.Add(d)
+	if c.auto {
+		c.now = at
+	}
+	c.timers = append(c.timers, fakeTimer{at, ch})
+	c.fire()
+	return ch
+}
+
+func (c *fakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	c.fire()
+}
+
+// fire fires the timers due. c.mu must be held.
+func (c *fakeClock) fire() {
+	c.timers = slices.DeleteFunc(c.timers, func(t fakeTimer) bool {
+		if t.at.After(c.now) {
+			return false
+		}
+		t.ch <- c.now
+		return true
+	})
+}
+
+// waitAfters waits until After has been called n times.
+func (c *fakeClock) waitAfters(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for c.afters < n {
+		c.cond.Wait()
+	}
+}
+
+func TestThrottle(t *testing.T) {
+	clock := newFakeClock(true)
+	start := clock.Now()
+	var times []time.Duration
+	for range Throttle(context.Background(), slices.Values([]int{1, 2, 3, 4, 5}), 10, 2, clock) {
+		times = append(times, clock.Now().Sub(start).Round(time.Millisecond))
+	}
+	if !slices.Equal(times, []time.Duration{0, 0, time.Millisecond * 100, time.Millisecond * 200, time.Millisecond * 300}) {
+		t.Fatal(times)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s := slices.Collect(Throttle(ctx, slices.Values([]int{1, 2}), 10, 2, clock)); len(s) != 0 {
+		t.Fatal(s)
+	}
+
+	var panicked any
+	func() {
+		defer func() {
+			panicked = recover()
+		}()
+		Throttle(context.Background(), Empty[int], 0, 1, nil)
+	}()
+	if panicked == nil {
+		t.Fatal("should panic")
+	}
+}
+
+func TestDebounce(t *testing.T) {
+	clock := newFakeClock(false)
+	quiet := time.Second
+	got := make(chan int, 1)
+	seq := func(yield func(int) bool) {
+		yield(1)
+		yield(2)
+		clock.waitAfters(2)
+		clock.Advance(quiet)
+		<-got
+		yield(3)
+	}
+	var s []int
+	for v := range Debounce(context.Background(), seq, quiet, clock) {
+		s = append(s, v)
+		got <- v
+	}
+	if !slices.Equal(s, []int{2, 3}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if s := slices.Collect(Take(Debounce(context.Background(), slices.Values([]int{1, 2, 3}), quiet, SystemClock), 1)); !slices.Equal(s, []int{3}) {
+		t.Fatal(s)
+	}
+}
+
+func TestSample(t *testing.T) {
+	clock := newFakeClock(false)
+	interval := time.Second
+	got := make(chan int, 1)
+	seq := func(yield func(int) bool) {
+		yield(1)
+		yield(2)
+		clock.Advance(interval)
+		<-got
+		yield(3)
+		yield(4)
+	}
+	var s []int
+	for v := range Sample(context.Background(), seq, interval, clock) {
+		s = append(s, v)
+		got <- v
+	}
+	if !slices.Equal(s, []int{2, 4}) {
+		t.Fatal(s)
+	}
+
+	// canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if s := slices.Collect(Sample(ctx, infiniteSeq(1), interval, clock)); len(s) != 0 {
+		t.Fatal(s)
+	}
+}
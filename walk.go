@@ -0,0 +1,349 @@
+package iter2
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	pathpkg "path"
+	"slices"
+	"strings"
+)
+
+// Name returns the name of the file or directory, that is, the last element of Path.
+func (dir *DirEntry) Name() string {
+	if dir.Entry != nil {
+		return dir.Entry.Name()
+	}
+	return pathpkg.Base(dir.Path)
+}
+
+// Info returns the FileInfo of the file or directory, by calling Entry.Info only once.
+// The result is cached for later calls, including the error.
+func (dir *DirEntry) Info() (fs.FileInfo, error) {
+	if dir.info == nil && dir.infoErr == nil {
+		if dir.Entry == nil {
+			dir.infoErr = &WalkError{Op: "info", Path: dir.Path, Err: fs.ErrInvalid}
+		} else if dir.info, dir.infoErr = dir.Entry.Info(); dir.infoErr != nil {
+			dir.infoErr = walkError("info", dir.Path, dir.infoErr)
+		}
+	}
+	return dir.info, dir.infoErr
+}
+
+// WalkError is the error yielded by the file tree iterators, such as [WalkDir].
+// Err is usually the [*fs.PathError] returned by the file system, so that
+// errors.As(err, &pathErr) still works. Use [errors.Is] instead of functions like
+// [os.IsNotExist] to test Err, because they do not unwrap WalkError.
+type WalkError struct {
+	Op   string // The operation that failed, such as "stat" and "readdir".
+	Path string // The path of the entry being walked, or the malformed pattern if Op is "match".
+	Err  error  // The underlying error.
+}
+
+// walkError returns a *WalkError of err.
+func walkError(op, path string, err error) error {
+	return &WalkError{Op: op, Path: path, Err: err}
+}
+
+func (e *WalkError) Error() string {
+	if pathErr, ok := e.Err.(*fs.PathError); ok && pathErr.Path == e.Path {
+		return e.Op + ": " + pathErr.Error() // avoid repeating the path
+	}
+	return e.Op + " " + e.Path + ": " + e.Err.Error()
+}
+
+func (e *WalkError) Unwrap() error {
+	return e.Err
+}
+
+// WalkDirOptions controls which entries are yielded by [WalkDirWith].
+// The zero value yields all entries, like [WalkDir].
+//
+// Patterns are matched against the path relative to the root, using the syntax of [path.Match],
+// except that a "**" path element matches zero or more path elements.
+// A pattern without slash matches the last element of the path at any depth.
+type WalkDirOptions struct {
+	// If MaxDepth > 0, the entries deeper than MaxDepth are not yielded,
+	// and the directories at depth MaxDepth are not read.
+	MaxDepth int
+	// The entries shallower than MinDepth are not yielded, but still walked.
+	MinDepth int
+	// If Include is not empty, only the entries matching any of the patterns are yielded.
+	// The directories not matching are still walked.
+	Include []string
+	// The entries matching any of the patterns are not yielded, and the directories matching are not walked.
+	Exclude []string
+	// If FilesOnly is true, only regular files are yielded.
+	FilesOnly bool
+	// If DirsOnly is true, only directories are yielded.
+	DirsOnly bool
+	// If SkipHidden is true, the entries whose names start with "." are not yielded,
+	// and the hidden directories are not walked. The root is never hidden.
+	SkipHidden bool
+	// IgnoreFiles are the names of ignore files, such as ".gitignore" and ".dockerignore",
+	// to be read from each directory walked. The entries ignored by them, with the semantics of
+	// .gitignore, are not yielded, and the directories ignored are not walked.
+	IgnoreFiles []string
+	// If BreadthFirst is true, the tree is walked in breadth-first order,
+	// that is, all the entries at depth n are yielded before the ones at depth n+1.
+	// Otherwise, the tree is walked in depth-first order, like [WalkDir].
+	BreadthFirst bool
+	// If Compare is not nil, the entries in the same directory are walked in the order decided by Compare,
+	// which returns a negative number when a < b, a positive number when a > b and zero when a == b.
+	// The entries comparing equal are walked in lexical order.
+	// Otherwise, the entries in the same directory are walked in lexical order.
+	Compare func(a, b fs.DirEntry) int
+	// OnError decides what to do with the errors encountered. See [ErrorPolicy].
+	OnError ErrorPolicy
+}
+
+// ErrorPolicy decides how [WalkDirWith] handles the errors encountered while walking,
+// such as reading a directory without permission.
+type ErrorPolicy int
+
+const (
+	// YieldErrors yields the errors, and lets the consumer decide whether to continue,
+	// by calling SkipDir or SkipAll of the DirEntry. This is the default.
+	YieldErrors ErrorPolicy = iota
+	// StopOnError yields the first error and stops walking.
+	StopOnError
+	// SkipUnreadable does not yield errors. The directories that can not be read are skipped,
+	// and the walk continues with the others. The error of the root, such as it does not exist,
+	// is still yielded with any ErrorPolicy, and nothing else is yielded.
+	SkipUnreadable
+	// CollectErrors is like SkipUnreadable, but when the walk completes, yields the root
+	// a last time with all the errors encountered, joined by [errors.Join].
+	CollectErrors
+)
+
+// DirsFirst is a Compare function of [WalkDirOptions] that orders directories before other entries.
+func DirsFirst(a, b fs.DirEntry) int {
+	switch {
+	case a.IsDir() && !b.IsDir():
+		return -1
+	case !a.IsDir() && b.IsDir():
+		return 1
+	}
+	return 0
+}
+
+// WalkDirBFS is like [WalkDir], but walks the tree in breadth-first order.
+// That is, all the entries at depth n are yielded before the ones at depth n+1.
+func WalkDirBFS(fsys fs.FS, root string) iter.Seq2[*DirEntry, error] {
+	return WalkDirWith(fsys, root, &WalkDirOptions{BreadthFirst: true})
+}
+
+// walkTree is like [fs.WalkDir], but walks the tree in the order decided by bfs and compare.
+// See [WalkDirOptions].
+func walkTree(fsys fs.FS, root string, bfs bool, compare func(a, b fs.DirEntry) int, fn fs.WalkDirFunc) error {
+	readDir := func(name string) ([]fs.DirEntry, error) {
+		entries, err := fs.ReadDir(fsys, name)
+		if compare != nil {
+			slices.SortStableFunc(entries, compare)
+		}
+		if err != nil {
+			err = walkError("readdir", name, err)
+		}
+		return entries, err
+	}
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		err = fn(root, nil, walkError("stat", root, err))
+	} else if bfs {
+		err = walkBFS(root, fs.FileInfoToDirEntry(info), readDir, fn)
+	} else {
+		err = walkDFS(root, fs.FileInfoToDirEntry(info), readDir, pathpkg.Join, fn)
+	}
+	if err == fs.SkipDir || err == fs.SkipAll {
+		return nil
+	}
+	return err
+}
+
+// walkDFS walks the tree rooted at name in depth-first order, the same way as [fs.WalkDir].
+// The paths are joined by join, such as [path.Join].
+func walkDFS(name string, d fs.DirEntry, readDir func(string) ([]fs.DirEntry, error), join func(elem ...string) string, fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir && d.IsDir() {
+			err = nil // successfully skipped directory
+		}
+		return err
+	}
+	entries, err := readDir(name)
+	if err != nil {
+		// Second call, to report ReadDir error.
+		if err = fn(name, d, err); err != nil {
+			if err == fs.SkipDir {
+				err = nil
+			}
+			return err
+		}
+	}
+	for _, entry := range entries {
+		if err := walkDFS(join(name, entry.Name()), entry, readDir, join, fn); err != nil {
+			if err == fs.SkipDir {
+				break
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// walkBFS walks the tree rooted at name in breadth-first order.
+func walkBFS(name string, d fs.DirEntry, readDir func(string) ([]fs.DirEntry, error), fn fs.WalkDirFunc) error {
+	if err := fn(name, d, nil); err != nil || !d.IsDir() {
+		if err == fs.SkipDir {
+			err = nil
+		}
+		return err
+	}
+	type queued struct {
+		name string
+		d    fs.DirEntry
+	}
+	queue := []queued{{name, d}}
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		entries, err := readDir(dir.name)
+		if err != nil {
+			// Second call, to report ReadDir error.
+			if err = fn(dir.name, dir.d, err); err == fs.SkipDir {
+				continue
+			} else if err != nil {
+				return err
+			}
+		}
+	entries:
+		for _, entry := range entries {
+			name := pathpkg.Join(dir.name, entry.Name())
+			switch err := fn(name, entry, nil); err {
+			case nil:
+				if entry.IsDir() {
+					queue = append(queue, queued{name, entry})
+				}
+			case fs.SkipDir:
+				if !entry.IsDir() {
+					break entries // skip the remaining entries in the directory
+				}
+			default:
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// relPath returns the slash-separated path of name relative to root, where name is root or in root.
+func relPath(root, name string) string {
+	if name == root {
+		return "."
+	}
+	if root == "." {
+		return name
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(name, root), "/")
+}
+
+// pathDepth returns the depth of the relative path rel.
+func pathDepth(rel string) int {
+	if rel == "." {
+		return 0
+	}
+	return strings.Count(rel, "/") + 1
+}
+
+// WalkDirWith is like [WalkDir], but only yields the entries selected by opts.
+// The directories not to be walked are pruned without being read.
+// Errors are always yielded. If any pattern in opts is malformed, WalkDirWith yields the root with nil Entry
+// and [path.ErrBadPattern] in a [*WalkError] with Op "match", and walks nothing.
+// Nil opts is the same as the zero value.
+func WalkDirWith(fsys fs.FS, root string, opts *WalkDirOptions) iter.Seq2[*DirEntry, error] {
+	if opts == nil {
+		opts = &WalkDirOptions{}
+	}
+	return func(yield func(*DirEntry, error) bool) {
+		for _, pattern := range append(opts.Include[:len(opts.Include):len(opts.Include)], opts.Exclude...) {
+			if err := validPattern(pattern); err != nil {
+				yield(&DirEntry{Path: root, RelPath: "."}, &WalkError{Op: "match", Path: pattern, Err: err})
+				return
+			}
+		}
+		var errs []error      // errors collected by CollectErrors
+		var rootD fs.DirEntry // entry of root, if any
+		var stopped bool      // whether yield returned false
+		// handleErr handles err of dir with opts.OnError, and returns the error to be returned to walkTree.
+		handleErr := func(dir *DirEntry, err error) error {
+			if dir.Entry == nil {
+				// The root can not be walked at all. Always report it.
+				yield(dir, err)
+				return fs.SkipAll
+			}
+			switch opts.OnError {
+			case StopOnError:
+				yield(dir, err)
+				return fs.SkipAll
+			case SkipUnreadable:
+				return fs.SkipDir
+			case CollectErrors:
+				errs = append(errs, err)
+				return fs.SkipDir
+			}
+			if !yield(dir, err) {
+				return fs.SkipAll // early stop. skip all.
+			}
+			return dir.err
+		}
+		ignores := make(ignoreRules)
+		walkTree(fsys, root, opts.BreadthFirst, opts.Compare, func(path string, d fs.DirEntry, err error) error {
+			rel := relPath(root, path)
+			dir := &DirEntry{Path: path, RelPath: rel, Entry: d, Depth: pathDepth(rel)}
+			if rel == "." {
+				rootD = d
+			}
+			if err != nil {
+				return handleErr(dir, err)
+			}
+			if rel != "." && (opts.SkipHidden && strings.HasPrefix(d.Name(), ".") ||
+				matchAny(opts.Exclude, rel) ||
+				ignores.ignored(rel, d.IsDir())) {
+				if d.IsDir() {
+					return fs.SkipDir
+				}
+				return nil
+			}
+			if d.IsDir() {
+				for _, name := range opts.IgnoreFiles {
+					name = pathpkg.Join(path, name)
+					data, err := fs.ReadFile(fsys, name)
+					if errors.Is(err, fs.ErrNotExist) {
+						continue
+					} else if err != nil {
+						if handleErr(dir, walkError("read", name, err)) == fs.SkipAll {
+							return fs.SkipAll
+						}
+						continue
+					}
+					ignores[rel] = append(ignores[rel], parseIgnore(data)...)
+				}
+			}
+			if dir.Depth >= opts.MinDepth &&
+				(len(opts.Include) == 0 || matchAny(opts.Include, rel)) &&
+				(!opts.FilesOnly || d.Type().IsRegular()) &&
+				(!opts.DirsOnly || d.IsDir()) {
+				if !yield(dir, nil) {
+					stopped = true
+					dir.err = fs.SkipAll // early stop. skip all.
+				}
+			}
+			if dir.err == nil && d.IsDir() && opts.MaxDepth > 0 && dir.Depth >= opts.MaxDepth {
+				return fs.SkipDir
+			}
+			return dir.err
+		})
+		if !stopped && len(errs) > 0 {
+			yield(&DirEntry{Path: root, RelPath: ".", Entry: rootD}, errors.Join(errs...))
+		}
+	}
+}
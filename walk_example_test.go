@@ -0,0 +1,116 @@
+package iter2_test
+
+import (
+	"fmt"
+	"os"
+	"testing/fstest"
+
+	"github.com/mkch/iter2"
+)
+
+func ExampleWalkDirWith() {
+	opts := &iter2.WalkDirOptions{
+		FilesOnly: true,
+		Exclude:   []string{"dir1"},
+	}
+	for d, err := range iter2.WalkDirWith(os.DirFS("testdata"), ".", opts) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(d.Path, d.Depth)
+	}
+	// Output:
+	// a 1
+	// b 1
+	// e 1
+}
+
+func ExampleWalkDirBFS() {
+	for d, err := range iter2.WalkDirBFS(os.DirFS("testdata"), ".") {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(d.Path)
+	}
+	// Output:
+	// .
+	// a
+	// b
+	// dir1
+	// e
+	// dir1/a
+}
+
+func ExampleGlob() {
+	fsys := fstest.MapFS{
+		"go.mod":              {},
+		"main.go":             {},
+		"cmd/tool/main.go":    {},
+		"internal/x/x.go":     {},
+		"internal/x/x.s":      {},
+		"testdata/in.txt":     {},
+		"testdata/out.golden": {},
+	}
+	for name, err := range iter2.Glob(fsys, "**/*.{go,s}", "testdata/*.txt") {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(name)
+	}
+	// Output:
+	// cmd/tool/main.go
+	// internal/x/x.go
+	// internal/x/x.s
+	// main.go
+	// testdata/in.txt
+}
+
+func ExampleFind() {
+	fsys := fstest.MapFS{
+		"main.go":           {Data: []byte("package main")},
+		"main_test.go":      {Data: []byte("package main")},
+		"empty.go":          {},
+		"vendor/lib/lib.go": {Data: []byte("package lib")},
+		"internal/x/x.go":   {Data: []byte("package x")},
+		"internal/x/README": {Data: []byte("x")},
+	}
+	pred := iter2.And(
+		iter2.Prune(iter2.Not(iter2.NameMatch("vendor"))), // do not walk vendor
+		iter2.Ext(".go"),
+		iter2.SizeAbove(0),
+		iter2.Not(iter2.NameMatch("*_test.go")),
+	)
+	for d, err := range iter2.Find(iter2.WalkDir(fsys, "."), pred) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(d.Path)
+	}
+	// Output:
+	// internal/x/x.go
+	// main.go
+}
+
+func ExampleDiffTrees() {
+	oldFS := fstest.MapFS{
+		"bin/app":    {Data: []byte("v1")},
+		"config.ini": {Data: []byte("a=1")},
+		"tmp/cache":  {},
+	}
+	newFS := fstest.MapFS{
+		"bin/app":    {Data: []byte("v2")},
+		"bin/tool":   {Data: []byte("v1")},
+		"config.ini": {Data: []byte("a=1")},
+	}
+	for diff, err := range iter2.DiffTreesWith(oldFS, ".", newFS, ".", &iter2.DiffOptions{CompareContent: true}) {
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(diff.Kind, diff.RelPath)
+	}
+	// Output:
+	// Modified bin/app
+	// Added bin/tool
+	// Removed tmp
+	// Removed tmp/cache
+}
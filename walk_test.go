@@ -0,0 +1,413 @@
+package iter2
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	"path"
+	"slices"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+type countInfoEntry struct {
+	fs.DirEntry
+	calls *int
+}
+
+func (e countInfoEntry) Info() (fs.FileInfo, error) {
+	*e.calls++
+	return e.DirEntry.Info()
+}
+
+func TestDirEntryInfo(t *testing.T) {
+	for d, err := range WalkDir(walkFS, "dir1") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Name() != path.Base(d.Path) {
+			t.Fatal(d.Name(), d.Path)
+		}
+		if d.Path != path.Join("dir1", d.RelPath) {
+			t.Fatal(d.Path, d.RelPath)
+		}
+		var calls int
+		d.Entry = countInfoEntry{d.Entry, &calls}
+		info1, err := d.Info()
+		if err != nil {
+			t.Fatal(err)
+		}
+		info2, _ := d.Info()
+		if info1 != info2 || calls != 1 || info1.IsDir() != d.Entry.IsDir() {
+			t.Fatal(d.Path, calls)
+		}
+	}
+}
+
+func TestWalkError(t *testing.T) {
+	err := walkError("readdir", "a/b", &fs.PathError{Op: "open", Path: "a/b", Err: fs.ErrPermission})
+	if err.Error() != "readdir: open a/b: "+fs.ErrPermission.Error() {
+		t.Fatal(err)
+	}
+	if !errors.Is(err, fs.ErrPermission) {
+		t.Fatal(err)
+	}
+	var pathErr *fs.PathError
+	if !errors.As(err, &pathErr) || pathErr.Op != "open" {
+		t.Fatal(err)
+	}
+	if err := walkError("read", "a", fs.ErrInvalid); err.Error() != "read a: "+fs.ErrInvalid.Error() {
+		t.Fatal(err)
+	}
+}
+
+var walkFS = fstest.MapFS{
+	"a.txt":            {},
+	".hidden/x.txt":    {},
+	"dir1/b.go":        {},
+	"dir1/.c.txt":      {},
+	"dir1/dir2/d.txt":  {},
+	"dir1/dir2/e.go":   {},
+	"vendor/lib/f.go":  {},
+	"vendor/lib/g.txt": {},
+	"dir3/dir4/dir5/h": {},
+	"dir3/dir4/dir5/i": {Mode: fs.ModeDir},
+}
+
+// walkPaths collects the paths yielded by seq.
+func walkPaths(t *testing.T, seq iter.Seq2[*DirEntry, error]) (s []string) {
+	t.Helper()
+	for d, err := range seq {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, d.Path)
+	}
+	return
+}
+
+func TestWalkDirWith(t *testing.T) {
+	s := walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{MaxDepth: 1}))
+	if !slices.Equal(s, []string{".", ".hidden", "a.txt", "dir1", "dir3", "vendor"}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{MinDepth: 3, MaxDepth: 3}))
+	if !slices.Equal(s, []string{"dir1/dir2/d.txt", "dir1/dir2/e.go", "dir3/dir4/dir5", "vendor/lib/f.go", "vendor/lib/g.txt"}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{Include: []string{"*.go"}, Exclude: []string{"vendor"}}))
+	if !slices.Equal(s, []string{"dir1/b.go", "dir1/dir2/e.go"}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{Include: []string{"dir1/**/*.txt"}}))
+	if !slices.Equal(s, []string{"dir1/.c.txt", "dir1/dir2/d.txt"}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(walkFS, "dir1", &WalkDirOptions{SkipHidden: true, FilesOnly: true}))
+	if !slices.Equal(s, []string{"dir1/b.go", "dir1/dir2/d.txt", "dir1/dir2/e.go"}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{SkipHidden: true, DirsOnly: true, Exclude: []string{"**/dir4"}}))
+	if !slices.Equal(s, []string{".", "dir1", "dir1/dir2", "dir3", "vendor", "vendor/lib"}) {
+		t.Fatal(s)
+	}
+
+	// consumer skip
+	s = nil
+	for d, err := range WalkDirWith(walkFS, ".", &WalkDirOptions{DirsOnly: true}) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d.Path == "dir1" {
+			d.SkipDir()
+		}
+		s = append(s, d.Path)
+	}
+	if !slices.Equal(s, []string{".", ".hidden", "dir1", "dir3", "dir3/dir4", "dir3/dir4/dir5", "dir3/dir4/dir5/i", "vendor", "vendor/lib"}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if s := walkPaths(t, Take2(WalkDirWith(walkFS, ".", &WalkDirOptions{FilesOnly: true}), 2)); !slices.Equal(s, []string{".hidden/x.txt", "a.txt"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestWalkDirWithDepth(t *testing.T) {
+	for d, err := range WalkDirWith(walkFS, "dir1", nil) {
+		if err != nil {
+			t.Fatal(err)
+		}
+		var depth int
+		switch d.Path {
+		case "dir1":
+			depth = 0
+		case "dir1/b.go", "dir1/.c.txt", "dir1/dir2":
+			depth = 1
+		default:
+			depth = 2
+		}
+		if d.Depth != depth {
+			t.Fatal(d.Path, d.Depth)
+		}
+	}
+}
+
+func TestWalkDirWithBadPattern(t *testing.T) {
+	var errs []error
+	for d, err := range WalkDirWith(walkFS, ".", &WalkDirOptions{Exclude: []string{"[a-"}}) {
+		if d.Path != "." || d.Entry != nil {
+			t.Fatal(d)
+		}
+		errs = append(errs, err)
+	}
+	var walkErr *WalkError
+	if len(errs) != 1 || !errors.Is(errs[0], path.ErrBadPattern) ||
+		!errors.As(errs[0], &walkErr) || walkErr.Op != "match" || walkErr.Path != "[a-" {
+		t.Fatal(errs)
+	}
+}
+
+func TestMatchPattern(t *testing.T) {
+	for _, c := range []struct {
+		pattern, name string
+		match         bool
+	}{
+		{"*.go", "a.go", true},
+		{"*.go", "dir/a.go", true},
+		{"*.go", "a.txt", false},
+		{"dir/*.go", "dir/a.go", true},
+		{"/dir/*.go", "dir/a.go", true},
+		{"dir/*.go", "x/dir/a.go", false},
+		{"**/dir/*.go", "x/dir/a.go", true},
+		{"**/dir/*.go", "dir/a.go", true},
+		{"dir/**", "dir/a/b/c", true},
+		{"dir/**", "dir", true},
+		{"dir/**/c", "dir/a/b/c", true},
+		{"dir/**/c", "dir/c", true},
+		{"dir/**/c", "dir/a/b", false},
+	} {
+		if match := matchPattern(c.pattern, c.name); match != c.match {
+			t.Fatal(c.pattern, c.name, match)
+		}
+	}
+}
+
+func TestWalkDirWithIgnoreFiles(t *testing.T) {
+	fsys := fstest.MapFS{
+		".gitignore": {Data: []byte(`# comment
+*.log
+!keep.log
+/build/
+docs/*.tmp
+cache/**
+\#hash
+`)},
+		"a.log":               {},
+		"keep.log":            {},
+		"#hash":               {},
+		"build/out":           {},
+		"src/build/x":         {},
+		"src/main.go":         {},
+		"src/.gitignore":      {Data: []byte("!a.log\n*.go\n")},
+		"src/a.log":           {},
+		"src/sub/b.go":        {},
+		"docs/a.tmp":          {},
+		"docs/sub/b.tmp":      {},
+		"cache/x/y":           {},
+		".dockerignore":       {Data: []byte("docs\n")},
+		"other/.dockerignore": {},
+	}
+	s := walkPaths(t, WalkDirWith(fsys, ".", &WalkDirOptions{IgnoreFiles: []string{".gitignore"}}))
+	if !slices.Equal(s, []string{
+		".", ".dockerignore", ".gitignore", "cache", "docs", "docs/sub", "docs/sub/b.tmp", "keep.log", "other", "other/.dockerignore",
+		"src", "src/.gitignore", "src/a.log", "src/build", "src/build/x", "src/sub",
+	}) {
+		t.Fatal(s)
+	}
+
+	s = walkPaths(t, WalkDirWith(fsys, ".", &WalkDirOptions{IgnoreFiles: []string{".gitignore", ".dockerignore"}, FilesOnly: true}))
+	if !slices.Equal(s, []string{
+		".dockerignore", ".gitignore", "keep.log", "other/.dockerignore", "src/.gitignore", "src/a.log", "src/build/x",
+	}) {
+		t.Fatal(s)
+	}
+}
+
+func TestIgnoreRules(t *testing.T) {
+	rules := ignoreRules{
+		".":   parseIgnore([]byte("*.o\n!important.o\nlogs/\n/root.txt\na/**/z\nname\\ \n")),
+		"sub": parseIgnore([]byte("!*.o\n")),
+	}
+	for _, c := range []struct {
+		rel     string
+		isDir   bool
+		ignored bool
+	}{
+		{"x.o", false, true},
+		{"dir/x.o", false, true},
+		{"important.o", false, false},
+		{"sub/x.o", false, false},
+		{"logs", true, true},
+		{"logs", false, false},
+		{"dir/logs", true, true},
+		{"root.txt", false, true},
+		{"dir/root.txt", false, false},
+		{"a/z", false, true},
+		{"a/b/c/z", false, true},
+		{"name ", false, true},
+		{"name", false, false},
+	} {
+		if ignored := rules.ignored(c.rel, c.isDir); ignored != c.ignored {
+			t.Fatal(c.rel, c.isDir, ignored)
+		}
+	}
+}
+
+func TestWalkDirBFS(t *testing.T) {
+	s := walkPaths(t, WalkDirBFS(walkFS, "."))
+	if !slices.Equal(s, []string{
+		".",
+		".hidden", "a.txt", "dir1", "dir3", "vendor",
+		".hidden/x.txt", "dir1/.c.txt", "dir1/b.go", "dir1/dir2", "dir3/dir4", "vendor/lib",
+		"dir1/dir2/d.txt", "dir1/dir2/e.go", "dir3/dir4/dir5", "vendor/lib/f.go", "vendor/lib/g.txt",
+		"dir3/dir4/dir5/h", "dir3/dir4/dir5/i",
+	}) {
+		t.Fatal(s)
+	}
+
+	// skip
+	s = nil
+	for d, err := range WalkDirBFS(walkFS, ".") {
+		if err != nil {
+			t.Fatal(err)
+		}
+		s = append(s, d.Path)
+		switch d.Path {
+		case "dir1", ".hidden/x.txt":
+			d.SkipDir()
+		case "vendor/lib":
+			d.SkipAll()
+		}
+	}
+	if !slices.Equal(s, []string{
+		".",
+		".hidden", "a.txt", "dir1", "dir3", "vendor",
+		".hidden/x.txt", "dir3/dir4", "vendor/lib",
+	}) {
+		t.Fatal(s)
+	}
+
+	// early stop
+	if s := walkPaths(t, Take2(WalkDirBFS(walkFS, "."), 2)); !slices.Equal(s, []string{".", ".hidden"}) {
+		t.Fatal(s)
+	}
+
+	// combined with other options
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{BreadthFirst: true, FilesOnly: true, MaxDepth: 2}))
+	if !slices.Equal(s, []string{"a.txt", ".hidden/x.txt", "dir1/.c.txt", "dir1/b.go"}) {
+		t.Fatal(s)
+	}
+}
+
+func TestWalkDirCompare(t *testing.T) {
+	s := walkPaths(t, WalkDirWith(walkFS, "dir1", &WalkDirOptions{Compare: DirsFirst}))
+	if !slices.Equal(s, []string{"dir1", "dir1/dir2", "dir1/dir2/d.txt", "dir1/dir2/e.go", "dir1/.c.txt", "dir1/b.go"}) {
+		t.Fatal(s)
+	}
+
+	reverse := func(a, b fs.DirEntry) int { return strings.Compare(b.Name(), a.Name()) }
+	s = walkPaths(t, WalkDirWith(walkFS, ".", &WalkDirOptions{Compare: reverse, BreadthFirst: true, MaxDepth: 1}))
+	if !slices.Equal(s, []string{".", "vendor", "dir3", "dir1", "a.txt", ".hidden"}) {
+		t.Fatal(s)
+	}
+}
+
+// unreadableFS is walkFS with the directories in unreadable failing to be read.
+type unreadableFS struct {
+	fstest.MapFS
+	unreadable []string
+}
+
+func (fsys unreadableFS) ReadDir(name string) ([]fs.DirEntry, error) {
+	if slices.Contains(fsys.unreadable, name) {
+		return nil, &fs.PathError{Op: "readdir", Path: name, Err: fs.ErrPermission}
+	}
+	return fsys.MapFS.ReadDir(name)
+}
+
+func TestWalkDirOnError(t *testing.T) {
+	fsys := unreadableFS{walkFS, []string{"dir1/dir2", "vendor"}}
+	walk := func(policy ErrorPolicy) (s []string, errs []error) {
+		for d, err := range WalkDirWith(fsys, ".", &WalkDirOptions{OnError: policy, FilesOnly: true}) {
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			s = append(s, d.Path)
+		}
+		return
+	}
+	want := []string{".hidden/x.txt", "a.txt", "dir1/.c.txt", "dir1/b.go", "dir3/dir4/dir5/h"}
+
+	s, errs := walk(YieldErrors)
+	if !slices.Equal(s, want) || len(errs) != 2 {
+		t.Fatal(s, errs)
+	}
+
+	s, errs = walk(StopOnError)
+	if !slices.Equal(s, want[:4]) || len(errs) != 1 || !errors.Is(errs[0], fs.ErrPermission) {
+		t.Fatal(s, errs)
+	}
+
+	s, errs = walk(SkipUnreadable)
+	if !slices.Equal(s, want) || len(errs) != 0 {
+		t.Fatal(s, errs)
+	}
+
+	s, errs = walk(CollectErrors)
+	if !slices.Equal(s, want) || len(errs) != 1 {
+		t.Fatal(s, errs)
+	}
+	joined, ok := errs[0].(interface{ Unwrap() []error })
+	if !ok {
+		t.Fatal(errs[0])
+	}
+	var paths []string
+	for _, err := range joined.Unwrap() {
+		var walkErr *WalkError
+		if !errors.As(err, &walkErr) || walkErr.Op != "readdir" {
+			t.Fatal(err)
+		}
+		paths = append(paths, walkErr.Path)
+	}
+	if !slices.Equal(paths, []string{"dir1/dir2", "vendor"}) {
+		t.Fatal(paths)
+	}
+
+	// early stop
+	for range WalkDirWith(fsys, ".", &WalkDirOptions{OnError: CollectErrors}) {
+		break
+	}
+
+	// root error
+	for _, policy := range []ErrorPolicy{YieldErrors, StopOnError, SkipUnreadable, CollectErrors} {
+		var errs []error
+		for d, err := range WalkDirWith(fsys, "NO THIS FILE", &WalkDirOptions{OnError: policy}) {
+			if d.Path != "NO THIS FILE" || d.Entry != nil {
+				t.Fatal(d)
+			}
+			errs = append(errs, err)
+		}
+		var walkErr *WalkError
+		if len(errs) != 1 || !errors.Is(errs[0], fs.ErrNotExist) || !errors.As(errs[0], &walkErr) || walkErr.Op != "stat" {
+			t.Fatal(policy, errs)
+		}
+	}
+}
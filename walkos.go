@@ -0,0 +1,133 @@
+package iter2
+
+import (
+	"errors"
+	"io/fs"
+	"iter"
+	"os"
+	"path/filepath"
+)
+
+// ErrSymlinkCycle is the error yielded by [WalkOS] for a symbolic link to a directory
+// that is being walked, which would make the walk endless.
+var ErrSymlinkCycle = errors.New("symbolic link cycle")
+
+// OSWalkOptions controls the walk of [WalkOS]. The zero value walks the tree like [WalkDir].
+type OSWalkOptions struct {
+	// If FollowSymlinks is true, symbolic links are resolved, and the directories they link to are walked.
+	// A symbolic link that can not be resolved is yielded with the error, in a [*WalkError] with Op "follow".
+	// A symbolic link to a directory being walked is yielded with [ErrSymlinkCycle], in a [*WalkError]
+	// with Op "follow", and is not walked.
+	FollowSymlinks bool
+	// If OneFileSystem is true, the directories on other file systems than the root are yielded,
+	// but not walked. OneFileSystem is only supported on Unix.
+	OneFileSystem bool
+}
+
+// linkEntry is a symbolic link resolved.
+type linkEntry struct {
+	fs.DirEntry       // the entry of the link target, or of the link itself if err is not nil
+	err         error // the error resolving the link
+}
+
+// WalkOS returns an iterator over the file tree rooted at the real path root of the operating system.
+// Unlike [WalkDir] over [os.DirFS], WalkOS can follow symbolic links. The root is always resolved
+// if it is a symbolic link. The Path of yielded DirEntry is cleaned by [filepath.Clean],
+// and uses the separator of the operating system.
+// Nil opts is the same as the zero value.
+func WalkOS(root string, opts *OSWalkOptions) iter.Seq2[*DirEntry, error] {
+	if opts == nil {
+		opts = &OSWalkOptions{}
+	}
+	root = filepath.Clean(root)
+	return func(yield func(*DirEntry, error) bool) {
+		info, err := os.Stat(root)
+		if err != nil {
+			yield(&DirEntry{Path: root, RelPath: "."}, walkError("stat", root, err))
+			return
+		}
+		rootDev, hasRootDev := device(info)
+
+		readDir := func(name string) ([]fs.DirEntry, error) {
+			entries, err := os.ReadDir(name)
+			if err != nil {
+				err = walkError("readdir", name, err)
+			}
+			if !opts.FollowSymlinks {
+				return entries, err
+			}
+			for i, entry := range entries {
+				if entry.Type()&fs.ModeSymlink == 0 {
+					continue
+				}
+				if info, err := os.Stat(filepath.Join(name, entry.Name())); err != nil {
+					entries[i] = &linkEntry{entry, walkError("follow", filepath.Join(name, entry.Name()), err)}
+				} else {
+					entries[i] = &linkEntry{fs.FileInfoToDirEntry(info), nil}
+				}
+			}
+			return entries, err
+		}
+
+		dirs := make(map[string]fs.FileInfo) // infos of the directories walked
+		isCycle := func(path string, info fs.FileInfo) bool {
+			for p := path; p != root; {
+				parent := filepath.Dir(p)
+				if parent == p {
+					break // p is not in root
+				}
+				if p = parent; os.SameFile(dirs[p], info) {
+					return true
+				}
+			}
+			return false
+		}
+
+		walkDFS(root, fs.FileInfoToDirEntry(info), readDir, filepath.Join, func(path string, d fs.DirEntry, err error) error {
+			rel := osRelPath(root, path)
+			dir := &DirEntry{Path: path, RelPath: rel, Entry: d, Depth: pathDepth(rel)}
+			if link, ok := d.(*linkEntry); ok {
+				dir.Entry = link.DirEntry
+				err = link.err
+			}
+			skip := false // whether to skip the directory after yielding it
+			if err == nil && d.IsDir() {
+				info, err1 := dir.Info()
+				switch {
+				case err1 != nil:
+					err = err1
+				case isCycle(path, info):
+					err = &WalkError{Op: "follow", Path: path, Err: ErrSymlinkCycle}
+				default:
+					dirs[path] = info
+					if dev, ok := device(info); opts.OneFileSystem && ok && hasRootDev && dev != rootDev {
+						skip = true
+					}
+				}
+				if err != nil {
+					// Do not walk a directory with error.
+					if !yield(dir, err) || dir.err == fs.SkipAll {
+						return fs.SkipAll
+					}
+					return fs.SkipDir
+				}
+			}
+			if !yield(dir, err) {
+				dir.err = fs.SkipAll // early stop. skip all.
+			}
+			if dir.err == nil && skip {
+				return fs.SkipDir
+			}
+			return dir.err
+		})
+	}
+}
+
+// osRelPath returns the slash-separated path of the OS path name relative to root.
+func osRelPath(root, name string) string {
+	rel, err := filepath.Rel(root, name)
+	if err != nil {
+		return "."
+	}
+	return filepath.ToSlash(rel)
+}
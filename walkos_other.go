@@ -0,0 +1,11 @@
+//go:build !unix
+
+package iter2
+
+import "io/fs"
+
+// device returns the ID of the device containing the file.
+// Device IDs are not supported on this platform.
+func device(info fs.FileInfo) (dev uint64, ok bool) {
+	return 0, false
+}
@@ -0,0 +1,158 @@
+package iter2
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"testing"
+	"time"
+)
+
+// makeOSTree creates a file tree with symbolic links in a temporary directory,
+// and returns the root of the tree.
+func makeOSTree(t *testing.T) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("symbolic links are not always available")
+	}
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	for _, dir := range []string{"root/a", "other/b"} {
+		if err := os.MkdirAll(filepath.Join(base, dir), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, file := range []string{"root/a/f", "other/b/g"} {
+		if err := os.WriteFile(filepath.Join(base, file), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for link, target := range map[string]string{
+		"root/a/cycle":  "..",
+		"root/broken":   "no_such_file",
+		"root/external": "../other",
+		"root/file":     "a/f",
+	} {
+		if err := os.Symlink(target, filepath.Join(base, link)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+type osWalkResult struct {
+	Path  string
+	Depth int
+	Err   error
+}
+
+func collectOSWalk(root string, opts *OSWalkOptions) (results []osWalkResult) {
+	for d, err := range WalkOS(root, opts) {
+		results = append(results, osWalkResult{d.RelPath, d.Depth, err})
+	}
+	return
+}
+
+func TestWalkOS(t *testing.T) {
+	root := makeOSTree(t)
+	s := collectOSWalk(root, nil)
+	if !slices.Equal(s, []osWalkResult{
+		{".", 0, nil}, {"a", 1, nil}, {"a/cycle", 2, nil}, {"a/f", 2, nil}, {"broken", 1, nil}, {"external", 1, nil}, {"file", 1, nil},
+	}) {
+		t.Fatal(s)
+	}
+}
+
+func TestWalkOSPath(t *testing.T) {
+	root := makeOSTree(t)
+	for d, err := range WalkOS(root, &OSWalkOptions{FollowSymlinks: true}) {
+		if err != nil {
+			continue
+		}
+		if d.Path != filepath.Join(root, filepath.FromSlash(d.RelPath)) {
+			t.Fatal(d.Path, d.RelPath)
+		}
+	}
+}
+
+func TestWalkOSFollowSymlinks(t *testing.T) {
+	root := makeOSTree(t)
+	s := collectOSWalk(root, &OSWalkOptions{FollowSymlinks: true})
+	paths := make([]string, len(s))
+	for i, r := range s {
+		paths[i] = r.Path
+		switch r.Path {
+		case "a/cycle":
+			if !errors.Is(r.Err, ErrSymlinkCycle) {
+				t.Fatal(r)
+			}
+		case "broken":
+			if !errors.Is(r.Err, fs.ErrNotExist) {
+				t.Fatal(r)
+			}
+		default:
+			if r.Err != nil {
+				t.Fatal(r)
+			}
+		}
+	}
+	if !slices.Equal(paths, []string{".", "a", "a/cycle", "a/f", "broken", "external", "external/b", "external/b/g", "file"}) {
+		t.Fatal(paths)
+	}
+
+	// skip and early stop
+	paths = nil
+	for d, err := range WalkOS(root, &OSWalkOptions{FollowSymlinks: true, OneFileSystem: true}) {
+		if err != nil {
+			continue
+		}
+		if filepath.Base(d.Path) == "external" {
+			d.SkipDir()
+		}
+		if filepath.Base(d.Path) == "file" {
+			break
+		}
+		rel, _ := filepath.Rel(root, d.Path)
+		paths = append(paths, filepath.ToSlash(rel))
+	}
+	if !slices.Equal(paths, []string{".", "a", "a/f", "external"}) {
+		t.Fatal(paths)
+	}
+}
+
+func TestWalkOSUncleanRoot(t *testing.T) {
+	root := makeOSTree(t)
+	want := collectOSWalk(root, &OSWalkOptions{FollowSymlinks: true})
+	for _, unclean := range []string{
+		root + string(filepath.Separator),
+		filepath.Join(root, "a") + string(filepath.Separator) + "..",
+	} {
+		done := make(chan []osWalkResult)
+		go func() {
+			done <- collectOSWalk(unclean, &OSWalkOptions{FollowSymlinks: true})
+		}()
+		select {
+		case s := <-done:
+			if !slices.EqualFunc(s, want, func(a, b osWalkResult) bool {
+				return a.Path == b.Path && a.Depth == b.Depth && (a.Err == nil) == (b.Err == nil)
+			}) {
+				t.Fatal(unclean, s)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("timeout", unclean)
+		}
+	}
+}
+
+func TestWalkOSErr(t *testing.T) {
+	var errs []error
+	for _, err := range WalkOS(filepath.Join(t.TempDir(), "no_such_file"), nil) {
+		errs = append(errs, err)
+	}
+	if len(errs) != 1 || !errors.Is(errs[0], fs.ErrNotExist) {
+		t.Fatal(errs)
+	}
+}
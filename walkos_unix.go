@@ -0,0 +1,17 @@
+//go:build unix
+
+package iter2
+
+import (
+	"io/fs"
+	"syscall"
+)
+
+// device returns the ID of the device containing the file.
+func device(info fs.FileInfo) (dev uint64, ok bool) {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return 0, false
+	}
+	return uint64(st.Dev), true
+}